@@ -0,0 +1,46 @@
+// Package cachefile reads and writes the JSON caches short-lived status line processes share
+// through the user cache directory
+package cachefile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Load decodes the JSON cache at path into v
+func Load(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Save encodes v as JSON to path, creating its directory. The file is replaced atomically,
+// since concurrent status line processes may save at the same time.
+func Save(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
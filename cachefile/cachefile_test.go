@@ -0,0 +1,52 @@
+package cachefile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cc-status-line", "cache.json")
+
+	var missing map[string]int
+	if err := Load(path, &missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of a missing file = %v, want ErrNotExist", err)
+	}
+
+	for _, want := range []map[string]int{{"a": 1}, {"b": 2}} {
+		if err := Save(path, want); err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]int
+		if err := Load(path, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got["a"] != want["a"] || got["b"] != want["b"] {
+			t.Errorf("Load() = %v, want %v", got, want)
+		}
+	}
+
+	// The temporary files are renamed over the cache
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("cache directory holds %d files, want only the cache", len(entries))
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]int
+	if err := Load(path, &got); err == nil {
+		t.Errorf("Load() of a corrupt file = %v, want an error", got)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+)
+
+const usage = `Usage: cc-status-line [command] [flags]
+
+Commands:
+  render          Read a status hook from stdin and print the status line (default)
+  preview [file]  Render every style against a status hook file (default: status-line.json)
+  themes list     List available styles and glyph sets
+  segments list   List the segments styles can render
+  install         Add the status line to Claude Code settings
+  uninstall       Remove the status line from Claude Code settings
+  doctor          Check git, fonts, colors and config
+  report          Sum cost, tokens and session time across sessions
+  daemon [start]  Serve renders from a background process with warm caches
+  daemon status   Show the running daemon and its sessions
+  daemon stop     Stop the running daemon
+  help            Show this message
+
+Run "cc-status-line <command> -h" for command flags.
+`
+
+// dispatch runs the subcommand named by the first argument.
+// Flags without a command select render, so "cc-status-line --style nerd" keeps working.
+func dispatch(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runRender(args)
+	}
+
+	command, rest := args[0], args[1:]
+	switch command {
+	case "render":
+		return runRender(rest)
+	case "preview":
+		return runPreview(rest)
+	case "themes":
+		return runList(command, rest, listThemes)
+	case "segments":
+		return runList(command, rest, listSegments)
+	case "install":
+		return runInstall(rest)
+	case "uninstall":
+		return runUninstall(rest)
+	case "doctor":
+		return runDoctor(rest)
+	case "daemon":
+		return runDaemon(rest)
+	case "report":
+		return runReport(rest)
+	case "help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+}
+
+// runList handles "<command> list", also accepting the bare command
+func runList(command string, args []string, list func()) int {
+	if len(args) > 0 && args[0] != "list" {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown %s subcommand %q (expected \"list\")\n", command, args[0])
+		return 2
+	}
+
+	list()
+	return 0
+}
+
+// listThemes prints the available styles and glyph sets
+func listThemes() {
+	fmt.Println("Styles:")
+	for _, style := range display.Styles {
+		fmt.Printf("  %-10s %s\n", style.Name, style.Description)
+	}
+
+	fmt.Println()
+	fmt.Println("Glyph sets:")
+	fmt.Printf("  %-10s %s\n", "auto", fmt.Sprintf("Detected from TERM and locale (currently %s)", formatters.DetectGlyphLevel()))
+	for _, level := range formatters.GlyphLevels {
+		glyphs := formatters.GlyphSetFor(level)
+		fmt.Printf("  %-10s %s\n", level, glyphSample(glyphs))
+	}
+}
+
+// listSegments prints the segments styles can render
+func listSegments() {
+	fmt.Println("Built-in:")
+	for _, segment := range display.Segments {
+		if !segment.Optional {
+			fmt.Printf("  %-14s %s\n", segment.Name, segment.Description)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Optional (enable with segments = [...] in the config file):")
+	for _, segment := range display.Segments {
+		if segment.Optional {
+			fmt.Printf("  %-14s %s\n", segment.Name, segment.Description)
+		}
+	}
+}
+
+// glyphSample renders a few characters of a glyph set so users can check their font
+func glyphSample(glyphs *formatters.GlyphSet) string {
+	bar := glyphs.HorizontalBar
+	partial := ""
+	if len(bar.Partial) > 4 {
+		partial = bar.Partial[4]
+	}
+
+	return fmt.Sprintf("%s  %s %s %s %s",
+		glyphs.WrapBar(strings.Repeat(bar.Full, 3)+partial+strings.Repeat(bar.Empty, 2)),
+		glyphs.Separator,
+		glyphs.Icons.Model,
+		glyphs.Icons.Branch,
+		glyphs.Icons.Clock)
+}
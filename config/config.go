@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvPath overrides the default config file location
+const EnvPath = "CC_STATUS_LINE_CONFIG"
+
+// Config holds the user's persistent preferences. Command line flags take precedence.
+type Config struct {
+	Style     string `toml:"style"`
+	Glyphs    string `toml:"glyphs"`
+	ParseMode string `toml:"parse_mode"`
+
+	// Segments lists the optional segments to show after the style's built-in ones, in order
+	Segments []string `toml:"segments"`
+
+	// Changes picks what the git segments count: "diff" for the working tree diff, or
+	// "session" for the lines Claude changed this session, shown by the edits segment
+	Changes string `toml:"changes"`
+
+	// ProtectedBranches are glob patterns of branches nobody should change directly; changes
+	// on one turn the branch segment into a warning
+	ProtectedBranches []string `toml:"protected_branches"`
+	ProtectedWarning  bool     `toml:"protected_warning"` // Also print a warning to stderr
+
+	// Danger lists regular expressions per infrastructure segment (kube, aws, gcloud, docker);
+	// a context matching one is shown in red
+	Danger map[string][]string `toml:"danger"`
+
+	// BlockTokenLimit is the token budget of a five-hour usage block, which the block
+	// segment measures against. 0 uses the most any block of the past week used.
+	BlockTokenLimit int `toml:"block_token_limit"`
+
+	// Rules restyle, show or hide segments depending on the status line data, e.g.
+	// when ctx.pct > 80 then ctx.style = "bold red blink"
+	Rules []string `toml:"rules"`
+
+	// Commands defines custom segments showing the output of shell commands
+	Commands []Command `toml:"commands"`
+
+	// AllowProjectCommands lets project config files define commands. Off by default, since
+	// any cloned repository could otherwise run code on every render. Only the user config
+	// can set it.
+	AllowProjectCommands bool `toml:"allow_project_commands"`
+}
+
+// DefaultDangerPattern marks production contexts: "prod" or "production" as a whole word
+// between dashes, underscores or dots, so "prod-eu" matches but "product-dev" doesn't
+const DefaultDangerPattern = `(^|[-_.])prod(uction)?($|[-_.])`
+
+// Command defaults, applied when the config file leaves them out
+const (
+	DefaultCommandTimeout = 500 * time.Millisecond
+	DefaultCommandTTL     = 10 * time.Second
+)
+
+// Command is a custom segment, enabled by listing its name in Segments
+type Command struct {
+	Name    string        `toml:"name"`
+	Run     string        `toml:"run"`     // Shell command line
+	Dir     string        `toml:"dir"`     // "project" (default), "cwd", or a path relative to the project directory
+	Timeout time.Duration `toml:"timeout"` // The segment shows an error marker when the command takes longer
+	TTL     time.Duration `toml:"ttl"`     // How long output is reused before the command runs again
+	Match   string        `toml:"match"`   // Regular expression extracting the text to show; its first group if it has one
+	Color   string        `toml:"color"`   // ANSI 256 color number or hex color
+	Bold    bool          `toml:"bold"`
+	Icon    string        `toml:"icon"`
+}
+
+// Default returns the configuration used when no config file exists
+func Default() *Config {
+	return &Config{
+		Style:     "classic",
+		Glyphs:    "auto",
+		ParseMode: "lenient",
+		Changes:   "diff",
+
+		ProtectedBranches: []string{"main", "master", "release/*"},
+		Danger: map[string][]string{
+			"kube":   {DefaultDangerPattern},
+			"aws":    {DefaultDangerPattern},
+			"gcloud": {DefaultDangerPattern},
+			"docker": {DefaultDangerPattern},
+		},
+	}
+}
+
+// Path returns the config file location: $CC_STATUS_LINE_CONFIG or <user config dir>/cc-status-line/config.toml
+func Path() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "cc-status-line", "config.toml"), nil
+}
+
+// Load reads the config file at path on top of the defaults.
+// A missing file is not an error; unknown keys are, so typos don't go unnoticed.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if _, err := decodeFile(path, cfg); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	cfg.applyCommandDefaults()
+	return cfg, nil
+}
+
+// decodeFile decodes the config file at path on top of cfg
+func decodeFile(path string, cfg *Config) (toml.MetaData, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return toml.MetaData{}, err
+	}
+	if err != nil {
+		return toml.MetaData{}, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	meta, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return meta, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return meta, fmt.Errorf("unknown keys in config %s: %s", path, strings.Join(keys, ", "))
+	}
+
+	return meta, nil
+}
+
+// applyCommandDefaults fills in the command settings the config file leaves out
+func (c *Config) applyCommandDefaults() {
+	for i := range c.Commands {
+		command := &c.Commands[i]
+		if command.Timeout <= 0 {
+			command.Timeout = DefaultCommandTimeout
+		}
+		if command.TTL <= 0 {
+			command.TTL = DefaultCommandTTL
+		}
+	}
+}
+
+// Validate reports the first problem that keeps the command from running
+func (c Command) Validate() error {
+	switch {
+	case c.Name == "":
+		return errors.New("command without a name")
+	case strings.ContainsAny(c.Name, " \t"):
+		return fmt.Errorf("command name %q contains whitespace", c.Name)
+	case strings.TrimSpace(c.Run) == "":
+		return fmt.Errorf("command %q has nothing to run", c.Name)
+	}
+	if _, err := regexp.Compile(c.Match); err != nil {
+		return fmt.Errorf("command %q has an invalid match pattern: %w", c.Name, err)
+	}
+	return nil
+}
+
+// LoadDefault loads the config file from its default location
+func LoadDefault() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Default(), err
+	}
+	return Load(path)
+}
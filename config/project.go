@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ProjectFile is the name of a project config file. It may also live in the project's
+// .claude directory as cc-status-line.toml.
+const ProjectFile = ".cc-status-line.toml"
+
+// ProjectFiles lists the project config files that apply to dir: those in dir and its
+// ancestors, outermost first, so nearer files override farther ones. Within a directory,
+// .cc-status-line.toml overrides .claude/cc-status-line.toml.
+func ProjectFiles(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for {
+		dirs = append(dirs, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	var files []string
+	for _, dir := range slices.Backward(dirs) {
+		for _, name := range []string{filepath.Join(".claude", "cc-status-line.toml"), ProjectFile} {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				files = append(files, path)
+			}
+		}
+	}
+	return files
+}
+
+// WithProject layers the project config files for dir over c, which is left unchanged.
+// Scalars and lists in a project file replace the user's, [danger] tables merge, and commands
+// replace the user's command of the same name. A file that fails to load is skipped and
+// reported in the returned error; the other files still apply.
+func (c *Config) WithProject(dir string) (*Config, []string, error) {
+	return c.WithFiles(ProjectFiles(dir))
+}
+
+// WithFiles layers project config files over c, farthest first, as WithProject does
+func (c *Config) WithFiles(files []string) (*Config, []string, error) {
+	if len(files) == 0 {
+		return c, nil, nil
+	}
+
+	cfg := c.clone()
+	var applied []string
+	var errs []error
+	for _, path := range files {
+		layer := cfg.clone()
+		layer.Commands = nil
+
+		meta, err := decodeFile(path, layer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		layer.AllowProjectCommands = c.AllowProjectCommands
+		commands := cfg.Commands
+		if meta.IsDefined("commands") {
+			if !c.AllowProjectCommands {
+				errs = append(errs, fmt.Errorf("%s defines commands, which run only with allow_project_commands = true in the user config", path))
+			} else {
+				commands = mergeCommands(commands, layer.Commands)
+			}
+		}
+		layer.Commands = commands
+
+		cfg = layer
+		applied = append(applied, path)
+	}
+
+	cfg.applyCommandDefaults()
+	return cfg, applied, errors.Join(errs...)
+}
+
+// mergeCommands replaces the commands in base with the same-named ones from layer and
+// appends the rest
+func mergeCommands(base, layer []Command) []Command {
+	merged := slices.Clone(base)
+	for _, command := range layer {
+		if i := slices.IndexFunc(merged, func(existing Command) bool { return existing.Name == command.Name }); i >= 0 {
+			merged[i] = command
+		} else {
+			merged = append(merged, command)
+		}
+	}
+	return merged
+}
+
+// clone returns a copy sharing nothing mutable with c
+func (c *Config) clone() *Config {
+	clone := *c
+	clone.Segments = slices.Clone(c.Segments)
+	clone.ProtectedBranches = slices.Clone(c.ProtectedBranches)
+	clone.Rules = slices.Clone(c.Rules)
+	clone.Commands = slices.Clone(c.Commands)
+	clone.Danger = maps.Clone(c.Danger)
+	for name, patterns := range clone.Danger {
+		clone.Danger[name] = slices.Clone(patterns)
+	}
+	return &clone
+}
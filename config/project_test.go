@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithProject(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "work", "api")
+	writeConfig(t, filepath.Join(root, "work", ProjectFile), `
+style = "minimal"
+segments = ["path", "kube"]
+
+[danger]
+kube = ["^live-"]
+`)
+	writeConfig(t, filepath.Join(project, ".claude", "cc-status-line.toml"), `
+style = "nerd"
+protected_branches = ["trunk"]
+`)
+	writeConfig(t, filepath.Join(project, ProjectFile), `
+segments = ["path", "toolchain"]
+`)
+
+	user := Default()
+	user.Commands = []Command{{Name: "kube", Run: "kubectl config current-context"}}
+	original := user.clone()
+
+	cfg, files, err := user.WithProject(filepath.Join(project))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFiles := []string{
+		filepath.Join(root, "work", ProjectFile),
+		filepath.Join(project, ".claude", "cc-status-line.toml"),
+		filepath.Join(project, ProjectFile),
+	}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("applied files = %v, want %v", files, wantFiles)
+	}
+
+	if cfg.Style != "nerd" {
+		t.Errorf("style = %q, want the nearest file's", cfg.Style)
+	}
+	if want := []string{"path", "toolchain"}; !reflect.DeepEqual(cfg.Segments, want) {
+		t.Errorf("segments = %v, want %v", cfg.Segments, want)
+	}
+	if want := []string{"trunk"}; !reflect.DeepEqual(cfg.ProtectedBranches, want) {
+		t.Errorf("protected branches = %v, want %v", cfg.ProtectedBranches, want)
+	}
+	if got := cfg.Danger["kube"]; !reflect.DeepEqual(got, []string{"^live-"}) {
+		t.Errorf("kube danger = %v, want the project's", got)
+	}
+	if got := cfg.Danger["aws"]; !reflect.DeepEqual(got, []string{DefaultDangerPattern}) {
+		t.Errorf("aws danger = %v, want the default kept", got)
+	}
+	if len(cfg.Commands) != 1 {
+		t.Errorf("commands = %v, want the user's kept", cfg.Commands)
+	}
+
+	if !reflect.DeepEqual(user, original) {
+		t.Error("WithProject modified the user config")
+	}
+}
+
+func TestWithProjectCommands(t *testing.T) {
+	project := t.TempDir()
+	writeConfig(t, filepath.Join(project, ProjectFile), `
+allow_project_commands = true
+
+[[commands]]
+name = "version"
+run = "make -s version"
+
+[[commands]]
+name = "kube"
+run = "kubectl config current-context --kubeconfig deploy/kubeconfig"
+`)
+
+	user := Default()
+	user.Commands = []Command{{Name: "kube", Run: "kubectl config current-context"}}
+
+	// Untrusted: the project's commands are dropped and can't enable themselves
+	cfg, _, err := user.WithProject(project)
+	if err == nil || !strings.Contains(err.Error(), "allow_project_commands") {
+		t.Errorf("err = %v, want a note about allow_project_commands", err)
+	}
+	if cfg.AllowProjectCommands || len(cfg.Commands) != 1 || cfg.Commands[0].Run != "kubectl config current-context" {
+		t.Errorf("untrusted project changed commands: %+v", cfg.Commands)
+	}
+
+	// Trusted: same-named commands are replaced, new ones appended with defaults
+	user.AllowProjectCommands = true
+	cfg, _, err = user.WithProject(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Commands) != 2 || !strings.Contains(cfg.Commands[0].Run, "deploy/kubeconfig") || cfg.Commands[1].Name != "version" {
+		t.Errorf("commands = %+v", cfg.Commands)
+	}
+	if cfg.Commands[1].Timeout != DefaultCommandTimeout {
+		t.Errorf("project command timeout = %s, want the default", cfg.Commands[1].Timeout)
+	}
+}
+
+func TestWithProjectSkipsBrokenFiles(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "api")
+	writeConfig(t, filepath.Join(root, ProjectFile), `style = "gradient"`)
+	writeConfig(t, filepath.Join(project, ProjectFile), `styel = "nerd"`)
+
+	cfg, files, err := Default().WithProject(project)
+	if err == nil || !strings.Contains(err.Error(), "styel") {
+		t.Errorf("err = %v, want the unknown key reported", err)
+	}
+	if cfg.Style != "gradient" || len(files) != 1 {
+		t.Errorf("style = %q from %v, want the valid file applied", cfg.Style, files)
+	}
+}
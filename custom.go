@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// runCommands runs the custom commands enabled in opts.Segments concurrently, so the slowest
+// one bounds the delay. A failing command is marked in failed and renders as an error marker;
+// one whose match pattern finds nothing is hidden. "cc-status-line doctor" shows the errors.
+// Invalid definitions never run and are reported on stderr like other config errors.
+func runCommands(hook *parser.StatusHook, opts renderOptions, failed map[string]bool) map[string]display.CommandOutput {
+	commands, errs := validCommands(opts.Commands)
+	for _, err := range errs {
+		opts.warn(err)
+	}
+
+	var enabled []config.Command
+	for _, command := range commands {
+		if slices.Contains(opts.Segments, command.Name) {
+			enabled = append(enabled, command)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	cache := opts.commandCache()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		outputs = make(map[string]display.CommandOutput)
+	)
+	for _, command := range enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			text, ok, err := runCommand(command, commandDir(command, hook), opts, cache)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[command.Name] = true
+			} else if !ok {
+				return
+			}
+			outputs[command.Name] = display.CommandOutput{Text: text, Icon: command.Icon, Color: command.Color, Bold: command.Bold}
+		}()
+	}
+	wg.Wait()
+
+	return outputs
+}
+
+// validCommands returns the commands that can run, in order, and the problem with each
+// one that can't: an invalid definition, a name defined twice or a built-in segment's name
+func validCommands(commands []config.Command) ([]config.Command, []error) {
+	var valid []config.Command
+	var errs []error
+	seen := make(map[string]bool)
+	for _, command := range commands {
+		err := command.Validate()
+		switch {
+		case err != nil:
+		case seen[command.Name]:
+			err = fmt.Errorf("command %q is defined twice", command.Name)
+		case isSegmentName(command.Name):
+			err = fmt.Errorf("command %q has the name of a built-in segment", command.Name)
+		}
+		seen[command.Name] = true
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		valid = append(valid, command)
+	}
+	return valid, errs
+}
+
+// runCommand returns the text one command shows, from the cache when its output is recent enough
+func runCommand(command config.Command, dir string, opts renderOptions, cache *metrics.CommandCache) (text string, ok bool, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logPanic(opts, command.Name, recovered, debug.Stack())
+			text, ok, err = "", false, fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	key := strings.Join([]string{command.Name, dir, command.Run}, "\x00")
+	output, err := cache.Get(key, command.TTL, func() (string, error) {
+		return metrics.RunCommand(command.Run, dir, opts.Environ, command.Timeout)
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	return metrics.ExtractOutput(output, command.Match)
+}
+
+// commandDir resolves the directory a command runs in
+func commandDir(command config.Command, hook *parser.StatusHook) string {
+	switch command.Dir {
+	case "", "project":
+		return projectDir(hook)
+	case "cwd":
+		return hook.Workspace.CurrentDir
+	}
+	if filepath.IsAbs(command.Dir) {
+		return command.Dir
+	}
+	return filepath.Join(projectDir(hook), command.Dir)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+func TestRunCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use sh")
+	}
+
+	command := func(name, run string) config.Command {
+		return config.Command{Name: name, Run: run, Timeout: time.Second, TTL: time.Minute}
+	}
+	hook := &parser.StatusHook{Workspace: parser.Workspace{CurrentDir: t.TempDir(), ProjectDir: t.TempDir()}}
+
+	var stderr strings.Builder
+	opts := renderOptions{
+		Segments: []string{"app", "broken", "empty", "cost", "hidden"},
+		Commands: []config.Command{
+			command("app", "echo 1.2.3"),
+			command("app", "echo shadowed"),
+			command("broken", "exit 3"),
+			command("empty", "  "),
+			command("cost", "echo not the cost segment"),
+			{Name: "hidden", Run: "echo none", Match: `\d+`, Timeout: time.Second, TTL: time.Minute},
+			command("disabled", "echo never"),
+		},
+		CommandCache: metrics.NewCommandCache(filepath.Join(t.TempDir(), "commands.json")),
+		Stderr:       &stderr,
+	}
+
+	failed := make(map[string]bool)
+	outputs := runCommands(hook, opts, failed)
+
+	want := map[string]display.CommandOutput{
+		"app":    {Text: "1.2.3"},
+		"broken": {},
+	}
+	if len(outputs) != len(want) {
+		t.Errorf("outputs = %+v, want %+v", outputs, want)
+	}
+	for name, output := range want {
+		if outputs[name] != output {
+			t.Errorf("outputs[%q] = %+v, want %+v", name, outputs[name], output)
+		}
+	}
+	if !failed["broken"] || len(failed) != 1 {
+		t.Errorf("failed = %v, want only broken", failed)
+	}
+
+	for _, problem := range []string{`"app" is defined twice`, `"empty" has nothing to run`, `"cost" has the name of a built-in segment`} {
+		if !strings.Contains(stderr.String(), problem) {
+			t.Errorf("stderr = %q, want %q reported", stderr.String(), problem)
+		}
+	}
+}
+
+func TestCommandDir(t *testing.T) {
+	hook := &parser.StatusHook{Workspace: parser.Workspace{CurrentDir: "/work/api/internal", ProjectDir: "/work/api"}}
+	absolute, _ := filepath.Abs("/opt/tools")
+
+	tests := map[string]string{
+		"":        "/work/api",
+		"project": "/work/api",
+		"cwd":     "/work/api/internal",
+		absolute:  absolute,
+		"scripts": filepath.Join("/work/api", "scripts"),
+		"../web":  filepath.Join("/work", "web"),
+	}
+	for dir, want := range tests {
+		if got := commandDir(config.Command{Dir: dir}, hook); got != want {
+			t.Errorf("commandDir(%q) = %q, want %q", dir, got, want)
+		}
+	}
+
+	// Without a project directory, project-relative paths start from the working directory
+	hook.Workspace.ProjectDir = ""
+	if got, want := commandDir(config.Command{Dir: "scripts"}, hook), filepath.Join("/work/api/internal", "scripts"); got != want {
+		t.Errorf("commandDir(scripts) without project = %q, want %q", got, want)
+	}
+}
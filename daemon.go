@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/daemon"
+	"github.com/DieGopherLT/cc-status-line/fswatch"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+// runDaemon handles "daemon [start|status|stop]"
+func runDaemon(args []string) int {
+	subcommand := "start"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("daemon "+subcommand, flag.ContinueOnError)
+	socket := fs.String("socket", daemon.SocketPath(), "Unix socket path (default from $"+daemon.EnvSocket+")")
+	gitTTL := fs.Duration("git-ttl", 2*time.Second, "How long git state is reused in repositories too large to watch (start only)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	switch subcommand {
+	case "start":
+		return startDaemon(*socket, *gitTTL)
+	case "status", "stop":
+		resp, err := daemon.Call(*socket, daemon.Request{Command: subcommand})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cc-status-line: no daemon running on %s\n", *socket)
+			return 1
+		}
+		fmt.Print(resp.Stdout)
+		fmt.Fprint(os.Stderr, resp.Stderr)
+		return resp.ExitCode
+	default:
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown daemon subcommand %q (expected start, status or stop)\n", subcommand)
+		return 2
+	}
+}
+
+// startDaemon serves render requests in the foreground until stopped or interrupted
+func startDaemon(socket string, gitTTL time.Duration) int {
+	watcher, err := fswatch.New()
+	if err != nil {
+		// Without watches the caches still work, expiring by time alone
+		fmt.Fprintf(os.Stderr, "cc-status-line warning: %v\n", err)
+	} else {
+		defer watcher.Close()
+	}
+
+	state := &daemonState{cfg: loadConfig()}
+	state.watchConfig(watcher)
+
+	sessions := daemon.NewSessions(watcher)
+	gitCache := metrics.NewGitCache(metrics.DefaultGitProvider, watcher, gitTTL)
+	commandCache := metrics.NewCommandCache("")
+	usageCache := sessionlog.NewCache("")
+
+	server := &daemon.Server{
+		Path:     socket,
+		Sessions: sessions,
+		Git:      gitCache,
+		Render: func(req daemon.Request) daemon.Response {
+			var stdout, stderr strings.Builder
+			cfg := withProjectConfig(state.config(), req.Input, &stderr)
+			opts, err := parseRenderFlags(req.Args, cfg, req.Getenv, &stderr)
+			if err != nil {
+				return daemon.Response{Stderr: stderr.String(), ExitCode: 2}
+			}
+			opts.GitInfo = gitCache.Get
+			opts.Observe = sessions.Record
+			opts.CommandCache = commandCache
+			opts.UsageCache = usageCache
+			opts.Environ = req.Env
+
+			code := render(req.Input, opts, &stdout, &stderr)
+			return daemon.Response{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: code}
+		},
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		server.Shutdown()
+	}()
+
+	if err := server.Listen(); err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "cc-status-line daemon listening on %s\n", socket)
+	if err := server.Serve(); err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// daemonState holds the config shared by all requests, reloaded when the file changes
+type daemonState struct {
+	mu  sync.Mutex
+	cfg *config.Config
+}
+
+func (d *daemonState) config() *config.Config {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cfg
+}
+
+// watchConfig reloads the config on changes. Editors usually replace files rather than
+// writing them in place, so the directory is watched instead of the file.
+func (d *daemonState) watchConfig(watcher *fswatch.Watcher) {
+	path, err := config.Path()
+	if err != nil || watcher == nil {
+		return
+	}
+	path = filepath.Clean(path)
+
+	_ = watcher.Watch(filepath.Dir(path), func(changed string) {
+		if changed != path {
+			return
+		}
+		cfg := loadConfig()
+		d.mu.Lock()
+		d.cfg = cfg
+		d.mu.Unlock()
+	})
+}
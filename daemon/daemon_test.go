@@ -0,0 +1,139 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/fswatch"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// startServer serves s on a temporary socket until the test ends
+func startServer(t *testing.T, s *Server) {
+	t.Helper()
+
+	s.Path = filepath.Join(t.TempDir(), "daemon.sock")
+	if err := s.Listen(); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Shutdown() })
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	server := &Server{Render: func(req Request) Response {
+		return Response{Stdout: string(req.Input) + " " + req.Getenv("TERM"), ExitCode: 3}
+	}}
+	startServer(t, server)
+
+	resp, err := Call(server.Path, Request{Command: CommandRender, Input: []byte("hook"), Env: []string{"TERM=xterm"}})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if resp.Stdout != "hook xterm" || resp.ExitCode != 3 {
+		t.Errorf("Call() = %+v, want stdout \"hook xterm\" and exit code 3", resp)
+	}
+
+	if err := (&Server{Path: server.Path}).Listen(); err == nil {
+		t.Error("second Listen() on a live socket succeeded")
+	}
+}
+
+func TestServerStop(t *testing.T) {
+	server := &Server{}
+	startServer(t, server)
+
+	if _, err := Call(server.Path, Request{Command: CommandStop}); err != nil {
+		t.Fatalf("Call(stop) error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(server.Path); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("socket still exists after stop")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := Call(server.Path, Request{Command: CommandStatus}); err == nil {
+		t.Error("Call() succeeded after stop")
+	}
+}
+
+func TestServerPanicClosesConnection(t *testing.T) {
+	server := &Server{Render: func(Request) Response { panic("boom") }}
+	startServer(t, server)
+
+	if _, err := Call(server.Path, Request{Command: CommandRender}); err == nil {
+		t.Error("Call() succeeded although the handler panicked")
+	}
+	if _, err := Call(server.Path, Request{Command: CommandStatus}); err != nil {
+		t.Errorf("daemon stopped answering after a panic: %v", err)
+	}
+}
+
+func TestSessionsHistory(t *testing.T) {
+	sessions := NewSessions(nil)
+	for i := range HistorySize + 5 {
+		hook := &parser.StatusHook{SessionID: "a", Cost: parser.Cost{TotalLinesAdded: i}}
+		sessions.Record(hook, &metrics.TokenMetrics{ContextPercentage: 10})
+	}
+	sessions.Record(&parser.StatusHook{SessionID: "b"}, nil)
+	sessions.Record(&parser.StatusHook{}, nil)
+
+	a, ok := sessions.Get("a")
+	if !ok {
+		t.Fatal("session a not recorded")
+	}
+	if a.Renders != HistorySize+5 || len(a.History) != HistorySize {
+		t.Errorf("Renders = %d, len(History) = %d, want %d and %d", a.Renders, len(a.History), HistorySize+5, HistorySize)
+	}
+	if first := a.History[0].LinesAdded; first != 5 {
+		t.Errorf("oldest sample has LinesAdded = %d, want 5", first)
+	}
+
+	snapshot := sessions.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].ID != "b" {
+		t.Errorf("Snapshot() = %d sessions starting with %q, want 2 starting with b", len(snapshot), snapshot[0].ID)
+	}
+}
+
+func TestSessionsSwitchTranscripts(t *testing.T) {
+	watcher, err := fswatch.New()
+	if err != nil {
+		t.Skipf("file watching unavailable: %v", err)
+	}
+	t.Cleanup(func() { watcher.Close() })
+
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.jsonl"), filepath.Join(dir, "second.jsonl")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions := NewSessions(watcher)
+	sessions.Record(&parser.StatusHook{SessionID: "a", TranscriptPath: first}, nil)
+	sessions.Record(&parser.StatusHook{SessionID: "b", TranscriptPath: first}, nil)
+
+	// The first transcript stays watched while another session still writes to it
+	sessions.Record(&parser.StatusHook{SessionID: "a", TranscriptPath: second}, nil)
+	if !watcher.Watching(first) || !watcher.Watching(second) {
+		t.Errorf("watching first %t, second %t; want both", watcher.Watching(first), watcher.Watching(second))
+	}
+
+	sessions.Record(&parser.StatusHook{SessionID: "b", TranscriptPath: second}, nil)
+	if watcher.Watching(first) {
+		t.Error("still watching the transcript both sessions switched away from")
+	}
+	if !watcher.Watching(second) {
+		t.Error("not watching the transcript the sessions switched to")
+	}
+}
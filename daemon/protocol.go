@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvSocket overrides the default socket location
+const EnvSocket = "CC_STATUS_LINE_SOCKET"
+
+// Commands understood by the daemon
+const (
+	CommandRender = "render" // Render Input with the render flags in Args
+	CommandStatus = "status" // Describe the daemon and its sessions
+	CommandStop   = "stop"   // Shut the daemon down
+)
+
+// Client deadlines: a missing daemon must fail fast so the fallback still renders in time,
+// and a stuck one must not hang Claude Code's status line
+const (
+	DialTimeout    = 50 * time.Millisecond
+	RequestTimeout = 2 * time.Second
+)
+
+// Request is one client call, sent as a single JSON document per connection
+type Request struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Input   []byte   `json:"input,omitempty"`
+	Env     []string `json:"env,omitempty"` // Client environment, for env-dependent segments and glyph detection
+}
+
+// Response is the daemon's reply, replayed by the client as if it had run the command itself
+type Response struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Getenv looks up a variable in the environment the client sent
+func (r Request) Getenv(key string) string {
+	prefix := key + "="
+	for _, entry := range r.Env {
+		if value, ok := strings.CutPrefix(entry, prefix); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// SocketPath returns the daemon socket: $CC_STATUS_LINE_SOCKET, else the user runtime directory,
+// else a per-user file in the temp directory
+func SocketPath() string {
+	if path := os.Getenv(EnvSocket); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "cc-status-line.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("cc-status-line-%d.sock", os.Getuid()))
+}
+
+// Call sends a request to the daemon listening on path and waits for its response.
+// Any error means the daemon is unavailable and the caller should handle the command itself.
+func Call(path string, req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, DialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("daemon not reachable: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(RequestTimeout)); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &resp, nil
+}
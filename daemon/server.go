@@ -0,0 +1,165 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+)
+
+// Server answers client requests on a Unix socket
+type Server struct {
+	Path     string
+	Render   func(req Request) Response // Handles CommandRender; runs concurrently for parallel clients
+	Sessions *Sessions                  // Optional, reported by CommandStatus
+	Git      *metrics.GitCache          // Optional, reported by CommandStatus
+
+	started  time.Time
+	requests atomic.Int64
+
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+// Listen creates the socket at s.Path. A stale socket left by a crashed daemon is replaced;
+// a live one is an error.
+func (s *Server) Listen() error {
+	if _, err := Call(s.Path, Request{Command: CommandStatus}); err == nil {
+		return fmt.Errorf("a daemon is already listening on %s", s.Path)
+	}
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.Path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Path, err)
+	}
+	// Requests carry the client environment, so only the owner may connect
+	if err := os.Chmod(s.Path, 0o600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		listener.Close()
+		return nil
+	}
+	s.listener = listener
+	s.started = time.Now()
+	return nil
+}
+
+// Serve answers requests on the socket opened by Listen until Shutdown
+func (s *Server) Serve() error {
+	s.mu.Lock()
+	listener := s.listener
+	s.mu.Unlock()
+	if listener == nil {
+		return nil
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go s.serve(conn)
+	}
+}
+
+// Shutdown stops accepting connections and removes the socket
+func (s *Server) Shutdown() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.listener == nil {
+		return nil
+	}
+	// Closing a Unix listener also unlinks its socket file
+	return s.listener.Close()
+}
+
+// serve answers one request. A panicking handler closes the connection without a reply,
+// which makes the client fall back to rendering in-process.
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	defer func() { _ = recover() }()
+
+	if err := conn.SetDeadline(time.Now().Add(RequestTimeout)); err != nil {
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	s.requests.Add(1)
+
+	var resp Response
+	switch req.Command {
+	case CommandRender:
+		resp = s.Render(req)
+	case CommandStatus:
+		resp = Response{Stdout: s.status()}
+	case CommandStop:
+		resp = Response{Stdout: "daemon stopping\n"}
+		defer s.Shutdown()
+	default:
+		resp = Response{Stderr: fmt.Sprintf("unknown daemon command %q\n", req.Command), ExitCode: 2}
+	}
+
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// status describes the daemon and the sessions it remembers
+func (s *Server) status() string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "socket:    %s\n", s.Path)
+	fmt.Fprintf(&out, "pid:       %d\n", os.Getpid())
+	fmt.Fprintf(&out, "uptime:    %s\n", time.Since(s.started).Round(time.Second))
+	fmt.Fprintf(&out, "requests:  %d\n", s.requests.Load())
+	if s.Git != nil {
+		fmt.Fprintf(&out, "git cache: %d directories\n", s.Git.Len())
+	}
+
+	if s.Sessions == nil {
+		return out.String()
+	}
+
+	sessions := s.Sessions.Snapshot()
+	fmt.Fprintf(&out, "sessions:  %d\n", len(sessions))
+	for _, session := range sessions {
+		fmt.Fprintf(&out, "  %s  %d renders, last %s ago", session.ID, session.Renders, time.Since(session.LastSeen).Round(time.Second))
+		if last := len(session.History) - 1; last >= 0 {
+			sample := session.History[last]
+			fmt.Fprintf(&out, ", $%.2f, context %.0f%%", sample.CostUSD, sample.ContextPercentage)
+		}
+		if !session.TranscriptUpdated.IsZero() {
+			fmt.Fprintf(&out, ", transcript written %s ago", time.Since(session.TranscriptUpdated).Round(time.Second))
+		}
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
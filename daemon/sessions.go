@@ -0,0 +1,166 @@
+package daemon
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/fswatch"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// History limits: each session keeps its last HistorySize samples and is forgotten
+// after SessionTTL without a render
+const (
+	HistorySize = 120
+	SessionTTL  = 24 * time.Hour
+)
+
+// Sample is the state of a session at one render
+type Sample struct {
+	Time              time.Time
+	CostUSD           float64
+	ContextPercentage float64
+	LinesAdded        int
+	LinesRemoved      int
+}
+
+// Session is what the daemon remembers about one Claude Code session
+type Session struct {
+	ID                string
+	TranscriptPath    string
+	FirstSeen         time.Time
+	LastSeen          time.Time
+	TranscriptUpdated time.Time // Last transcript write seen by the watcher, zero if none yet
+	Renders           int
+	History           []Sample // Oldest first
+}
+
+// Sessions holds the history of every session rendered recently
+type Sessions struct {
+	watcher *fswatch.Watcher // Optional; without it TranscriptUpdated stays zero
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewSessions creates an empty session history
+func NewSessions(watcher *fswatch.Watcher) *Sessions {
+	return &Sessions{watcher: watcher, sessions: make(map[string]*Session)}
+}
+
+// Record adds a render of hook to its session's history
+func (s *Sessions) Record(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics) {
+	if hook == nil || hook.SessionID == "" {
+		return
+	}
+
+	now := time.Now()
+	sample := Sample{
+		Time:         now,
+		CostUSD:      hook.Cost.TotalCostUSD,
+		LinesAdded:   hook.Cost.TotalLinesAdded,
+		LinesRemoved: hook.Cost.TotalLinesRemoved,
+	}
+	if tokenMetrics != nil {
+		sample.ContextPercentage = tokenMetrics.ContextPercentage
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	session, ok := s.sessions[hook.SessionID]
+	if !ok {
+		session = &Session{ID: hook.SessionID, FirstSeen: now}
+		s.sessions[hook.SessionID] = session
+	}
+	session.LastSeen = now
+	session.Renders++
+	session.History = append(session.History, sample)
+	if len(session.History) > HistorySize {
+		session.History = slices.Delete(session.History, 0, len(session.History)-HistorySize)
+	}
+
+	if hook.TranscriptPath != "" && hook.TranscriptPath != session.TranscriptPath {
+		previous := session.TranscriptPath
+		session.TranscriptPath = hook.TranscriptPath
+		s.unwatchTranscript(previous)
+		s.watchTranscript(hook.TranscriptPath)
+	}
+}
+
+// Get returns a copy of one session
+func (s *Sessions) Get(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	return copySession(session), true
+}
+
+// Snapshot returns a copy of every session, most recently seen first
+func (s *Sessions) Snapshot() []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, copySession(session))
+	}
+	slices.SortFunc(sessions, func(a, b Session) int { return b.LastSeen.Compare(a.LastSeen) })
+	return sessions
+}
+
+// prune forgets idle sessions and stops watching their transcripts. Callers hold s.mu.
+func (s *Sessions) prune(now time.Time) {
+	for id, session := range s.sessions {
+		if now.Sub(session.LastSeen) > SessionTTL {
+			delete(s.sessions, id)
+			s.unwatchTranscript(session.TranscriptPath)
+		}
+	}
+}
+
+// watchTranscript tracks writes to a transcript. Callers hold s.mu.
+func (s *Sessions) watchTranscript(path string) {
+	if s.watcher == nil || s.watcher.Watching(path) {
+		return
+	}
+
+	_ = s.watcher.Watch(path, func(string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		now := time.Now()
+		for _, session := range s.sessions {
+			if session.TranscriptPath == path {
+				session.TranscriptUpdated = now
+			}
+		}
+	})
+}
+
+// unwatchTranscript stops watching a transcript no session uses anymore. Callers hold s.mu.
+func (s *Sessions) unwatchTranscript(path string) {
+	if s.watcher == nil || path == "" {
+		return
+	}
+	for _, session := range s.sessions {
+		if session.TranscriptPath == path {
+			return
+		}
+	}
+	s.watcher.Unwatch(path)
+}
+
+func copySession(session *Session) Session {
+	copied := *session
+	copied.History = slices.Clone(session.History)
+	return copied
+}
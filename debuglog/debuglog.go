@@ -0,0 +1,59 @@
+package debuglog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Rotation limits: the log is rotated once it exceeds MaxSize, keeping MaxBackups old files (debug.log.1, ...)
+const (
+	MaxSize    = 1 << 20
+	MaxBackups = 3
+)
+
+// DefaultPath returns the debug log location inside the user cache directory
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "cc-status-line", "debug.log")
+}
+
+// Append writes a timestamped entry to the log at path, creating and rotating it as needed
+func Append(path, title, body string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > MaxSize {
+		rotate(path)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+	defer file.Close()
+
+	entry := fmt.Sprintf("=== %s %s ===\n%s\n", time.Now().Format(time.RFC3339), title, strings.TrimRight(body, "\n"))
+	if _, err := file.WriteString(entry + "\n"); err != nil {
+		return fmt.Errorf("failed to write debug log: %w", err)
+	}
+
+	return nil
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, and moves path to path.1.
+// Errors are ignored: concurrent status line processes may race to rotate, and losing
+// a debug log is preferable to failing the render.
+func rotate(path string) {
+	_ = os.Remove(fmt.Sprintf("%s.%d", path, MaxBackups))
+	for i := MaxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	_ = os.Rename(path, path+".1")
+}
@@ -0,0 +1,79 @@
+package display
+
+// StyleInfo describes a selectable status line style
+type StyleInfo struct {
+	Name        string
+	Description string
+}
+
+// SegmentInfo describes a piece of information shown on the status line
+type SegmentInfo struct {
+	Name        string
+	Source      string // Data source the segment is built from; a failing source marks the segment as broken
+	Description string
+	Optional    bool // Rendered only when listed in the config's segments, after the style's built-in segments
+}
+
+// Styles lists every style accepted by NewFormatter, in display order
+var Styles = []StyleInfo{
+	{Name: "classic", Description: "Labeled sections with pipe separators (default)"},
+	{Name: "gradient", Description: "Height-variable context bar colored by usage"},
+	{Name: "compact", Description: "Icon prefixes and a 20-character context bar"},
+	{Name: "minimal", Description: "Space separated values without decorations"},
+	{Name: "nerd", Description: "Bordered panel with absolute token counts"},
+}
+
+// Segments lists every segment a style may render
+var Segments = []SegmentInfo{
+	{Name: "model", Source: "hook", Description: "Display name of the active Claude model"},
+	{Name: "git-branch", Source: "git", Description: "Checked out branch, tag or detached commit"},
+	{Name: "git-changes", Source: "git", Description: "Lines added and removed in the working tree compared to HEAD"},
+	{Name: "git-files", Source: "git", Description: "Conflicted, staged, modified and untracked file counts"},
+	{Name: "git-location", Source: "git", Description: "Linked worktree and its repository, enclosing superproject, dirty submodules"},
+	{Name: "output-style", Source: "hook", Description: "Active Claude Code output style"},
+	{Name: "version", Source: "hook", Description: "Claude Code version"},
+	{Name: "context", Source: "tokens", Description: "Context window usage bar and percentage"},
+	{Name: "path", Source: "hook", Description: "Project name and directory inside it, warning when outside the project", Optional: true},
+	{Name: "toolchain", Source: "toolchain", Description: "Language versions declared by the project, active virtualenv and Node version", Optional: true},
+	{Name: "edits", Source: "hook", Description: "Lines Claude added and removed this session, across commits", Optional: true},
+	{Name: "cost", Source: "hook", Description: "Session cost in USD", Optional: true},
+	{Name: "duration", Source: "hook", Description: "Wall-clock time since the session started", Optional: true},
+	{Name: "api-time", Source: "hook", Description: "Time spent waiting on API responses", Optional: true},
+	{Name: "api-ratio", Source: "hook", Description: "API time as a percentage of the session's wall-clock time", Optional: true},
+	{Name: "usage-day", Source: "usage", Description: "Cost, tokens and session time of every session today", Optional: true},
+	{Name: "usage-week", Source: "usage", Description: "Cost, tokens and session time of every session this week", Optional: true},
+	{Name: "block", Source: "usage", Description: "Tokens used in the current five-hour usage block, projected use and time left", Optional: true},
+	{Name: "kube", Source: "kube", Description: "Current Kubernetes context and namespace from kubeconfig", Optional: true},
+	{Name: "aws", Source: "aws", Description: "Active AWS profile and region", Optional: true},
+	{Name: "gcloud", Source: "gcloud", Description: "Active gcloud configuration and project", Optional: true},
+	{Name: "docker", Source: "docker", Description: "Docker context, when not the default", Optional: true},
+}
+
+// IsStyle reports whether name is a known style
+func IsStyle(name string) bool {
+	for _, style := range Styles {
+		if style.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOptionalSegment reports whether name is a segment that can be enabled in the config
+func IsOptionalSegment(name string) bool {
+	for _, segment := range Segments {
+		if segment.Name == name {
+			return segment.Optional
+		}
+	}
+	return false
+}
+
+// MarkSourceFailed flags every segment built from source in failed
+func MarkSourceFailed(failed map[string]bool, source string) {
+	for _, segment := range Segments {
+		if segment.Source == source {
+			failed[segment.Name] = true
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+// ExtraData is everything optional segments are built from
+type ExtraData struct {
+	Hook *parser.StatusHook
+	Home string // Home directory of the user running Claude Code, shown as "~"
+
+	Toolchains []metrics.Toolchain
+	Commands   map[string]CommandOutput      // Keyed by command name
+	Usage      map[string]*sessionlog.Totals // Keyed by segment name: usage-day, usage-week
+	Block      *sessionlog.BlockStatus       // Current five-hour usage block, nil if none is open
+
+	Protected bool // Changes on a protected branch
+
+	Infra  map[string]*metrics.InfraContext // Keyed by segment name
+	Danger map[string][]*regexp.Regexp      // Patterns marking an infrastructure context as dangerous
+}
+
+// CommandOutput is the text of a custom command segment and how to show it
+type CommandOutput struct {
+	Text  string
+	Icon  string
+	Color string
+	Bold  bool
+}
+
+// Extras builds the optional segments listed in names, in that order. Names that are not
+// built-in refer to custom commands; unknown names are skipped and doctor reports them.
+func Extras(names []string, data ExtraData) []formatters.Segment {
+	var segments []formatters.Segment
+	for _, name := range names {
+		switch name {
+		case "path":
+			segments = append(segments, formatters.PathSegment(data.Hook.Workspace, data.Home))
+		case "edits":
+			segments = append(segments, formatters.EditsSegment(data.Hook.Cost))
+		case "cost":
+			segments = append(segments, formatters.CostSegment(data.Hook.Cost))
+		case "duration":
+			segments = append(segments, formatters.DurationSegment(data.Hook.Cost))
+		case "api-time":
+			segments = append(segments, formatters.APITimeSegment(data.Hook.Cost))
+		case "api-ratio":
+			segments = append(segments, formatters.APIRatioSegment(data.Hook.Cost))
+		case "toolchain":
+			segments = append(segments, formatters.ToolchainSegment(data.Toolchains))
+		case "usage-day", "usage-week":
+			segments = append(segments, usageSegment(name, data.Usage[name]))
+		case "block":
+			segments = append(segments, blockSegment(data.Block))
+		case "kube", "aws", "gcloud", "docker":
+			context := data.Infra[name]
+			segments = append(segments, formatters.InfraSegment(name, context, isDangerous(context, data.Danger[name])))
+		default:
+			if output, ok := data.Commands[name]; ok {
+				segments = append(segments, formatters.CommandSegment(name, output.Icon, output.Text, output.Color, output.Bold))
+			}
+		}
+	}
+	return segments
+}
+
+// usageLabels names the period each usage segment covers
+var usageLabels = map[string]string{"usage-day": "today", "usage-week": "week"}
+
+// usageSegment shows the totals of a usage segment's period; nil totals hide it
+func usageSegment(name string, totals *sessionlog.Totals) formatters.Segment {
+	if totals == nil {
+		totals = &sessionlog.Totals{}
+	}
+	return formatters.UsageSegment(name, usageLabels[name], totals.CostUSD, totals.Tokens.Total(), totals.Duration)
+}
+
+// blockSegment shows the current usage block; nil hides it
+func blockSegment(status *sessionlog.BlockStatus) formatters.Segment {
+	if status == nil {
+		status = &sessionlog.BlockStatus{}
+	}
+	return formatters.BlockSegment(status.Tokens, status.Projected, status.Limit, status.Remaining)
+}
+
+// isDangerous reports whether the context's name or detail matches one of patterns
+func isDangerous(context *metrics.InfraContext, patterns []*regexp.Regexp) bool {
+	if context == nil {
+		return false
+	}
+	for _, re := range patterns {
+		if re.MatchString(context.Name) || re.MatchString(context.Detail) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileDanger compiles the danger patterns of each infrastructure segment. Invalid
+// patterns are left out and returned as one error.
+func CompileDanger(danger map[string][]string) (map[string][]*regexp.Regexp, error) {
+	compiled := make(map[string][]*regexp.Regexp, len(danger))
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(danger)) {
+		for _, pattern := range danger[name] {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid danger pattern %q for %s: %w", pattern, name, err))
+				continue
+			}
+			compiled[name] = append(compiled[name], re)
+		}
+	}
+	return compiled, errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+)
+
+func TestIsDangerousDefaultPattern(t *testing.T) {
+	danger, err := CompileDanger(config.Default().Danger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"prod":              true,
+		"prod-eu":           true,
+		"eu-west-prod":      true,
+		"payments.prod.k8s": true,
+		"app_production":    true,
+		"production":        true,
+		"product-staging":   false,
+		"reproduce":         false,
+		"preprod":           false,
+		"prodigy":           false,
+		"staging":           false,
+	}
+	for name, want := range tests {
+		if got := isDangerous(&metrics.InfraContext{Name: name}, danger["kube"]); got != want {
+			t.Errorf("isDangerous(%q) = %t, want %t", name, got, want)
+		}
+		if got := isDangerous(&metrics.InfraContext{Name: "dev", Detail: name}, danger["aws"]); got != want {
+			t.Errorf("isDangerous(detail %q) = %t, want %t", name, got, want)
+		}
+	}
+
+	if isDangerous(nil, danger["kube"]) {
+		t.Error("isDangerous(nil) = true")
+	}
+}
+
+func TestCompileDanger(t *testing.T) {
+	danger, err := CompileDanger(map[string][]string{"kube": {"^live-", "(unclosed"}, "aws": {"prod"}})
+	if err == nil {
+		t.Fatal("CompileDanger() accepted an invalid pattern")
+	}
+	if len(danger["kube"]) != 1 || len(danger["aws"]) != 1 {
+		t.Errorf("CompileDanger() = %v, want the valid patterns kept", danger)
+	}
+}
@@ -1,6 +1,8 @@
 package display
 
 import (
+	"runtime/debug"
+
 	"github.com/DieGopherLT/cc-status-line/display/formatters"
 	"github.com/DieGopherLT/cc-status-line/metrics"
 	"github.com/DieGopherLT/cc-status-line/parser"
@@ -19,21 +21,35 @@ type StatusLineFormatter interface {
 }
 
 // NewFormatter creates a formatter based on the style name
-func NewFormatter(style string) StatusLineFormatter {
+func NewFormatter(style string, opts formatters.Options) StatusLineFormatter {
 	switch style {
 	case "gradient":
-		return &formatters.GradientFormatter{}
+		return &formatters.GradientFormatter{Options: opts}
 	case "compact":
-		return &formatters.CompactFormatter{}
+		return &formatters.CompactFormatter{Options: opts}
 	case "minimal":
-		return &formatters.MinimalFormatter{}
+		return &formatters.MinimalFormatter{Options: opts}
 	case "nerd":
-		return &formatters.NerdFormatter{}
+		return &formatters.NerdFormatter{Options: opts}
 	default:
-		return &formatters.ClassicFormatter{}
+		return &formatters.ClassicFormatter{Options: opts}
 	}
 }
 
+// FormatSafely runs a formatter, turning a panic outside the per-segment guards into an error marker
+func FormatSafely(formatter StatusLineFormatter, opts formatters.Options, hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) (statusLine string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if opts.OnPanic != nil {
+				opts.OnPanic("render", recovered, debug.Stack())
+			}
+			statusLine = opts.ErrorMarker("render")
+		}
+	}()
+
+	return formatter.Format(hook, tokenMetrics, gitInfo)
+}
+
 // FormatStatusLine is a convenience function that uses the classic formatter
 // Kept for backward compatibility
 func FormatStatusLine(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string {
@@ -0,0 +1,36 @@
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+func BenchmarkFormat(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join("testdata", "hooks", "basic.json"))
+	if err != nil {
+		b.Fatalf("failed to read fixture: %v", err)
+	}
+	hook, _, err := parser.Decode(data, parser.ParseStrict)
+	if err != nil {
+		b.Fatalf("invalid fixture: %v", err)
+	}
+
+	tokenMetrics := metrics.CalculateTokenMetrics(hook.ContextWindow)
+	gitInfo := &metrics.GitInfo{IsGitRepo: true, Branch: "main", BranchDisplay: "main", HasChanges: true, ChangesText: "(+156 -23)", Additions: 156, Deletions: 23}
+
+	for _, style := range Styles {
+		b.Run(style.Name, func(b *testing.B) {
+			formatter := NewFormatter(style.Name, formatters.Options{Glyphs: formatters.UnicodeGlyphs})
+
+			b.ReportAllocs()
+			for b.Loop() {
+				formatter.Format(hook, tokenMetrics, gitInfo)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package formatters
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// blockTotalBlocks is the width of the usage block bar
+const blockTotalBlocks = 10
+
+// BlockSegment shows the current five-hour usage block: tokens used against limit with a
+// bar, the share expected by the block's end, and the time left, e.g.
+// "block ██████░░░░ 61% ~92% 2h14m left". Without a limit it shows token counts instead.
+// The segment turns yellow when the block is projected past 80% of the limit and red past 100%.
+func BlockSegment(tokens, projected, limit int, remaining time.Duration) Segment {
+	return Segment{
+		Name: "block",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if tokens == 0 {
+				return "", "", blockStyle
+			}
+			left := HumanizeDuration(remaining.Truncate(time.Minute)) + " left"
+			if limit <= 0 {
+				return "", fmt.Sprintf("block %s tok ~%s %s", HumanizeTokens(tokens), HumanizeTokens(projected), left), blockStyle
+			}
+
+			percent := float64(tokens) / float64(limit) * 100
+			projectedPercent := float64(projected) / float64(limit) * 100
+			style := blockStyle
+			switch {
+			case projectedPercent >= 100:
+				style = blockDangerStyle
+			case projectedPercent >= 80:
+				style = blockWarningStyle
+			}
+
+			// The bar takes the segment's style, so it is drawn without styles of its own
+			plain := lipgloss.NewStyle()
+			bar := glyphs.WrapBar(RenderProgressBar(percent, blockTotalBlocks, glyphs.HorizontalBar, plain, plain))
+			return "", fmt.Sprintf("block %s %d%% ~%d%% %s", bar, int(percent), int(projectedPercent), left), style
+		},
+	}
+}
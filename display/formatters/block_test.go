@@ -0,0 +1,48 @@
+package formatters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockSegment(t *testing.T) {
+	remaining := 2*time.Hour + 14*time.Minute + 30*time.Second
+
+	tests := []struct {
+		name    string
+		segment Segment
+		glyphs  *GlyphSet
+		want    string
+	}{
+		{
+			name:    "with a limit",
+			segment: BlockSegment(610_000, 920_000, 1_000_000, remaining),
+			glyphs:  ASCIIGlyphs,
+			want:    "block [######----] 61% ~92% 2h14m left",
+		},
+		{
+			name:    "without a limit",
+			segment: BlockSegment(1_200_000, 3_400_000, 0, remaining),
+			glyphs:  UnicodeGlyphs,
+			want:    "block 1.2M tok ~3.4M 2h14m left",
+		},
+		{
+			name:    "no open block",
+			segment: BlockSegment(0, 0, 1_000_000, 0),
+			glyphs:  UnicodeGlyphs,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got, _ := tt.segment.Render(tt.glyphs); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, _, style := BlockSegment(900_000, 1_100_000, 1_000_000, remaining).Render(UnicodeGlyphs); style.GetForeground() != blockDangerStyle.GetForeground() {
+		t.Error("a block projected past its limit should use the danger style")
+	}
+}
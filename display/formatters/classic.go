@@ -15,47 +15,70 @@ const (
 )
 
 // ClassicFormatter implements the original status line style
-type ClassicFormatter struct{}
+type ClassicFormatter struct {
+	Options
+}
 
 // Format creates the formatted status line output in the classic style
 func (f *ClassicFormatter) Format(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string {
 	var segments []string
+	icons := f.glyphs().Icons
 
 	// Model info (always present - required field)
-	modelSegment := fmt.Sprintf("Model: %s", hook.Model.DisplayName)
-	segments = append(segments, modelStyle.Render(modelSegment))
-
-	// Git branch (only if git repo detected)
-	if gitInfo != nil && gitInfo.IsGitRepo {
-		gitBranchSegment := fmt.Sprintf("/ %s", gitInfo.BranchDisplay)
-		segments = append(segments, branchStyle.Render(gitBranchSegment))
-
-		// Git changes (only if git repo detected)
-		segments = append(segments, f.formatGitChanges(gitInfo))
+	segments = f.appendSegment(segments, "model", func() string {
+		modelSegment := f.label(icons.ModelIcon(hook.Model), fmt.Sprintf("Model: %s", hook.Model.DisplayName))
+		return modelStyle.Render(modelSegment)
+	})
+
+	// Git branch and changes (only if git repo detected)
+	if gitInfo != nil && gitInfo.IsGitRepo || f.Failed["git-branch"] {
+		segments = f.appendSegment(segments, "git-branch", func() string {
+			return f.branchLabel(gitInfo, func(icon, text string) string {
+				return fmt.Sprintf("%s %s", f.iconOr(icon, "/"), text)
+			})
+		})
+		segments = f.appendSegment(segments, "git-changes", func() string {
+			return f.formatGitChanges(gitInfo)
+		})
+		segments = f.appendSegment(segments, "git-files", func() string {
+			return f.formatGitFiles(gitInfo)
+		})
+		segments = f.appendSegment(segments, "git-location", func() string {
+			return f.formatGitLocation(gitInfo)
+		})
 	}
 
 	// Output style (only if present)
-	if hook.OutputStyle.Name != "" {
-		styleSegment := fmt.Sprintf("Style: %s", hook.OutputStyle.Name)
-		segments = append(segments, styleColor.Render(styleSegment))
-	}
+	segments = f.appendSegment(segments, "output-style", func() string {
+		if hook.OutputStyle.Name == "" {
+			return ""
+		}
+		styleSegment := f.label(icons.Style, fmt.Sprintf("Style: %s", hook.OutputStyle.Name))
+		return styleColor.Render(styleSegment)
+	})
 
 	// Version (always present - required field)
-	versionSegment := fmt.Sprintf("v%s", hook.Version)
-	segments = append(segments, blueStyle.Render(versionSegment))
+	segments = f.appendSegment(segments, "version", func() string {
+		versionSegment := f.label(icons.Version, fmt.Sprintf("v%s", hook.Version))
+		return blueStyle.Render(versionSegment)
+	})
+
+	// Optional segments enabled in the config
+	segments = f.appendExtra(segments, false)
 
 	// Context visualization (only if context data available)
-	if tokenMetrics != nil && tokenMetrics.ContextPercentage > 0 {
-		contextSegment := f.formatContextVisualization(tokenMetrics)
-		segments = append(segments, contextSegment)
-	}
+	segments = f.appendSegment(segments, "context", func() string {
+		if tokenMetrics == nil || tokenMetrics.ContextPercentage <= 0 {
+			return ""
+		}
+		return f.formatContextVisualization(tokenMetrics)
+	})
 
 	// Join all segments with separator
 	statusLine := strings.Join(segments, grayStyle.Render(classicSeparator))
 
 	// Add subtle horizontal lines for visual breathing room
-	width := lipgloss.Width(statusLine)
-	line := lineStyle.Render(strings.Repeat("─", width))
+	line := f.rule(lipgloss.Width(statusLine))
 
 	return line + "\n" + statusLine + "\n" + line
 }
@@ -65,6 +88,9 @@ func (f *ClassicFormatter) formatGitChanges(gitInfo *metrics.GitInfo) string {
 	if !gitInfo.IsGitRepo {
 		return grayStyle.Render("(no git)")
 	}
+	if f.HideGitDiff {
+		return ""
+	}
 
 	text := gitInfo.ChangesText
 	if strings.Contains(text, "+") && strings.Contains(text, "-") {
@@ -89,7 +115,8 @@ func (f *ClassicFormatter) formatGitChanges(gitInfo *metrics.GitInfo) string {
 func (f *ClassicFormatter) formatContextVisualization(tokenMetrics *metrics.TokenMetrics) string {
 	percentage := tokenMetrics.ContextPercentage
 
-	bar := RenderProgressBar(percentage, classicTotalBlocks, HorizontalBlocks, whiteStyle, dimStyle)
+	glyphs := f.glyphs()
+	bar := glyphs.WrapBar(RenderProgressBar(percentage, classicTotalBlocks, glyphs.HorizontalBar, whiteStyle, dimStyle))
 
-	return fmt.Sprintf("Ctx: %s %d%%", bar, int(percentage))
+	return f.label(glyphs.Icons.Context, fmt.Sprintf("Ctx: %s %d%%", bar, int(percentage)))
 }
@@ -0,0 +1,20 @@
+package formatters
+
+import "github.com/charmbracelet/lipgloss"
+
+// CommandSegment shows the output of a custom command from the config. color is an ANSI
+// 256 color number or hex color; empty uses the default light gray.
+func CommandSegment(name, icon, text, color string, bold bool) Segment {
+	style := commandStyle
+	if color != "" {
+		style = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	}
+	style = style.Bold(bold)
+
+	return Segment{
+		Name: name,
+		Render: func(*GlyphSet) (string, string, lipgloss.Style) {
+			return icon, text, style
+		},
+	}
+}
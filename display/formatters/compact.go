@@ -9,62 +9,68 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-const (
-	compactTotalBlocks = 20
-	compactFilledBlock = "█"
-	compactEmptyBlock  = "░"
-)
-
-// Unicode icons for compact style
-const (
-	iconModel   = "❋"
-	iconStyle   = "⎔"
-	iconVersion = "⌘"
-	iconContext = "◐"
-	iconBranch  = "⎇"
-	iconAdd     = "↑"
-	iconDel     = "↓"
-)
+const compactTotalBlocks = 20
 
-// CompactFormatter implements a compact style with Unicode icons
-type CompactFormatter struct{}
+// CompactFormatter implements a compact style with icons from the active glyph set
+type CompactFormatter struct {
+	Options
+}
 
 // Format creates a compact status line with icons
 func (f *CompactFormatter) Format(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string {
 	var parts []string
+	glyphs := f.glyphs()
 
 	// Model with icon
-	modelPart := fmt.Sprintf("%s %s", iconModel, hook.Model.DisplayName)
-	parts = append(parts, modelStyle.Render(modelPart))
+	parts = f.appendSegment(parts, "model", func() string {
+		modelPart := fmt.Sprintf("%s %s", glyphs.Icons.ModelIcon(hook.Model), hook.Model.DisplayName)
+		return modelStyle.Render(modelPart)
+	})
 
 	// Git with icon and arrows
-	if gitInfo != nil && gitInfo.IsGitRepo {
-		gitPart := f.formatGitInfo(gitInfo)
-		parts = append(parts, gitPart)
+	if gitInfo != nil && gitInfo.IsGitRepo || f.Failed["git-branch"] {
+		parts = f.appendSegment(parts, "git-branch", func() string {
+			return f.formatGitInfo(gitInfo)
+		})
+		parts = f.appendSegment(parts, "git-files", func() string {
+			return f.formatGitFiles(gitInfo)
+		})
+		parts = f.appendSegment(parts, "git-location", func() string {
+			return f.formatGitLocation(gitInfo)
+		})
 	}
 
 	// Output style with icon
-	if hook.OutputStyle.Name != "" {
-		stylePart := fmt.Sprintf("%s %s", iconStyle, hook.OutputStyle.Name)
-		parts = append(parts, styleColor.Render(stylePart))
-	}
+	parts = f.appendSegment(parts, "output-style", func() string {
+		if hook.OutputStyle.Name == "" {
+			return ""
+		}
+		stylePart := fmt.Sprintf("%s %s", glyphs.Icons.Style, hook.OutputStyle.Name)
+		return styleColor.Render(stylePart)
+	})
 
 	// Version with icon
-	versionPart := fmt.Sprintf("%s %s", iconVersion, hook.Version)
-	parts = append(parts, blueStyle.Render(versionPart))
+	parts = f.appendSegment(parts, "version", func() string {
+		versionPart := fmt.Sprintf("%s %s", glyphs.Icons.Version, hook.Version)
+		return blueStyle.Render(versionPart)
+	})
+
+	// Optional segments enabled in the config
+	parts = f.appendExtra(parts, true)
 
 	// Context with icon and wider bar (last for visual balance)
-	if tokenMetrics != nil && tokenMetrics.ContextPercentage > 0 {
-		contextPart := f.formatContextBar(tokenMetrics)
-		parts = append(parts, contextPart)
-	}
+	parts = f.appendSegment(parts, "context", func() string {
+		if tokenMetrics == nil || tokenMetrics.ContextPercentage <= 0 {
+			return ""
+		}
+		return f.formatContextBar(tokenMetrics)
+	})
 
 	// Join with double space
 	statusLine := strings.Join(parts, "  ")
 
 	// Add subtle horizontal lines for visual breathing room
-	width := lipgloss.Width(statusLine)
-	line := lineStyle.Render(strings.Repeat("─", width))
+	line := f.rule(lipgloss.Width(statusLine))
 
 	return line + "\n" + statusLine + "\n" + line
 }
@@ -72,18 +78,16 @@ func (f *CompactFormatter) Format(hook *parser.StatusHook, tokenMetrics *metrics
 // formatContextBar creates a 20-character context bar
 func (f *CompactFormatter) formatContextBar(tokenMetrics *metrics.TokenMetrics) string {
 	percentage := tokenMetrics.ContextPercentage
+	glyphs := f.glyphs()
 
 	// Calculate filled blocks (each block = 5%)
-	filledCount := int(percentage / 5)
-	if filledCount > compactTotalBlocks {
-		filledCount = compactTotalBlocks
-	}
+	filledCount := int(clampPercentage(percentage) / 5)
 
 	// Build bar
-	filledBar := whiteStyle.Render(strings.Repeat(compactFilledBlock, filledCount))
-	emptyBar := dimStyle.Render(strings.Repeat(compactEmptyBlock, compactTotalBlocks-filledCount))
+	filledBar := whiteStyle.Render(strings.Repeat(glyphs.HorizontalBar.Full, filledCount))
+	emptyBar := dimStyle.Render(strings.Repeat(glyphs.HorizontalBar.Empty, compactTotalBlocks-filledCount))
 
-	return fmt.Sprintf("%s %d%% [%s%s]", iconContext, int(percentage), filledBar, emptyBar)
+	return fmt.Sprintf("%s %d%% [%s%s]", glyphs.Icons.Context, int(percentage), filledBar, emptyBar)
 }
 
 // formatGitInfo formats git with branch icon and arrows for changes
@@ -92,20 +96,24 @@ func (f *CompactFormatter) formatGitInfo(gitInfo *metrics.GitInfo) string {
 		return ""
 	}
 
+	glyphs := f.glyphs()
+
 	// Branch with icon
-	branch := branchStyle.Render(fmt.Sprintf("%s %s", iconBranch, gitInfo.BranchDisplay))
+	branch := f.branchLabel(gitInfo, func(icon, text string) string {
+		return fmt.Sprintf("%s %s", icon, text)
+	})
 
 	// Changes with arrows
 	var changes string
-	if gitInfo.Additions > 0 || gitInfo.Deletions > 0 {
+	if !f.HideGitDiff && (gitInfo.Additions > 0 || gitInfo.Deletions > 0) {
 		if gitInfo.Additions > 0 {
-			changes += greenStyle.Render(fmt.Sprintf("%s%d", iconAdd, gitInfo.Additions))
+			changes += greenStyle.Render(fmt.Sprintf("%s%d", glyphs.ArrowUp, gitInfo.Additions))
 		}
 		if gitInfo.Deletions > 0 {
 			if changes != "" {
 				changes += " "
 			}
-			changes += redStyle.Render(fmt.Sprintf("%s%d", iconDel, gitInfo.Deletions))
+			changes += redStyle.Render(fmt.Sprintf("%s%d", glyphs.ArrowDown, gitInfo.Deletions))
 		}
 		return branch + " " + changes
 	}
@@ -0,0 +1,21 @@
+package formatters
+
+import (
+	"fmt"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CostSegment shows the session cost, e.g. "$1.24"
+func CostSegment(cost parser.Cost) Segment {
+	return Segment{
+		Name: "cost",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if glyphs.IconsInAllStyles {
+				return glyphs.Icons.Dollar, fmt.Sprintf("%.2f", cost.TotalCostUSD), costStyle
+			}
+			return "", fmt.Sprintf("$%.2f", cost.TotalCostUSD), costStyle
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package formatters
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DurationSegment shows the session's wall-clock time, e.g. "1h23m"
+func DurationSegment(cost parser.Cost) Segment {
+	return Segment{
+		Name: "duration",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if cost.TotalDurationMS <= 0 {
+				return "", "", durationStyle
+			}
+			text := HumanizeDuration(time.Duration(cost.TotalDurationMS) * time.Millisecond)
+			if glyphs.IconsInAllStyles {
+				return glyphs.Icons.Clock, text, durationStyle
+			}
+			return "", text, durationStyle
+		},
+	}
+}
+
+// APITimeSegment shows the time spent waiting on API responses, e.g. "api 12m"
+func APITimeSegment(cost parser.Cost) Segment {
+	return Segment{
+		Name: "api-time",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if cost.TotalAPIDurationMS <= 0 {
+				return "", "", apiTimeStyle
+			}
+			text := HumanizeDuration(time.Duration(cost.TotalAPIDurationMS) * time.Millisecond)
+			if glyphs.IconsInAllStyles {
+				return glyphs.Icons.Hourglass, text, apiTimeStyle
+			}
+			return "", "api " + text, apiTimeStyle
+		},
+	}
+}
+
+// APIRatioSegment shows API time as a share of the session's wall-clock time, e.g. "api 14%".
+// A low share on a long session means it mostly sat idle. Parallel requests can push it
+// past 100%.
+func APIRatioSegment(cost parser.Cost) Segment {
+	return Segment{
+		Name: "api-ratio",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if cost.TotalDurationMS <= 0 {
+				return "", "", apiTimeStyle
+			}
+			text := fmt.Sprintf("%.0f%%", APIRatio(cost))
+			if glyphs.IconsInAllStyles {
+				return glyphs.Icons.Hourglass, text, apiTimeStyle
+			}
+			return "", "api " + text, apiTimeStyle
+		},
+	}
+}
+
+// APIRatio returns API time as a percentage of wall-clock time, or 0 before any time has passed
+func APIRatio(cost parser.Cost) float64 {
+	if cost.TotalDurationMS <= 0 {
+		return 0
+	}
+	return float64(cost.TotalAPIDurationMS) / float64(cost.TotalDurationMS) * 100
+}
+
+// HumanizeDuration formats d with its two largest units, e.g. "45s", "4m32s", "1h23m" or "2d5h".
+// A zero second unit is dropped, so an hour reads "1h".
+func HumanizeDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%ds", max(seconds, 0))
+	case seconds < 3600:
+		return twoUnits(seconds/60, "m", seconds%60, "s")
+	case seconds < 86400:
+		return twoUnits(seconds/3600, "h", seconds%3600/60, "m")
+	default:
+		return twoUnits(seconds/86400, "d", seconds%86400/3600, "h")
+	}
+}
+
+func twoUnits(major int64, majorUnit string, minor int64, minorUnit string) string {
+	if minor == 0 {
+		return fmt.Sprintf("%d%s", major, majorUnit)
+	}
+	return fmt.Sprintf("%d%s%d%s", major, majorUnit, minor, minorUnit)
+}
@@ -0,0 +1,57 @@
+package formatters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0s"},
+		{d: 45*time.Second + 400*time.Millisecond, want: "45s"},
+		{d: 4*time.Minute + 32*time.Second, want: "4m32s"},
+		{d: 12 * time.Minute, want: "12m"},
+		{d: time.Hour + 23*time.Minute + 59*time.Second, want: "1h23m"},
+		{d: 2 * time.Hour, want: "2h"},
+		{d: 53 * time.Hour, want: "2d5h"},
+	}
+
+	for _, tt := range tests {
+		if got := HumanizeDuration(tt.d); got != tt.want {
+			t.Errorf("HumanizeDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestDurationSegments(t *testing.T) {
+	cost := parser.Cost{TotalDurationMS: 83 * 60 * 1000, TotalAPIDurationMS: 12 * 60 * 1000}
+
+	tests := []struct {
+		segment Segment
+		glyphs  *GlyphSet
+		want    string
+	}{
+		{segment: DurationSegment(cost), glyphs: UnicodeGlyphs, want: "1h23m"},
+		{segment: APITimeSegment(cost), glyphs: UnicodeGlyphs, want: "api 12m"},
+		{segment: APIRatioSegment(cost), glyphs: UnicodeGlyphs, want: "api 14%"},
+		{segment: APIRatioSegment(cost), glyphs: NerdFontGlyphs, want: "14%"},
+	}
+
+	for _, tt := range tests {
+		if _, got, _ := tt.segment.Render(tt.glyphs); got != tt.want {
+			t.Errorf("%s.Render(%s) = %q, want %q", tt.segment.Name, tt.glyphs.Level, got, tt.want)
+		}
+	}
+
+	// Hidden until the hook reports any time
+	for _, segment := range []Segment{DurationSegment(parser.Cost{}), APITimeSegment(parser.Cost{}), APIRatioSegment(parser.Cost{})} {
+		if _, got, _ := segment.Render(UnicodeGlyphs); got != "" {
+			t.Errorf("%s.Render() without timings = %q, want empty", segment.Name, got)
+		}
+	}
+}
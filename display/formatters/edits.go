@@ -0,0 +1,23 @@
+package formatters
+
+import (
+	"fmt"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// EditsSegment shows the lines Claude added and removed this session, e.g. "✎ +156 -23".
+// Unlike the git diff it keeps counting across commits. The edit marker is shown with
+// every glyph set so the two can't be confused.
+func EditsSegment(cost parser.Cost) Segment {
+	return Segment{
+		Name: "edits",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if cost.TotalLinesAdded == 0 && cost.TotalLinesRemoved == 0 {
+				return "", "", editsStyle
+			}
+			return "", fmt.Sprintf("%s +%d -%d", glyphs.Icons.Edit, cost.TotalLinesAdded, cost.TotalLinesRemoved), editsStyle
+		},
+	}
+}
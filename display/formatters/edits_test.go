@@ -0,0 +1,52 @@
+package formatters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestEditsSegment(t *testing.T) {
+	cost := parser.Cost{TotalLinesAdded: 156, TotalLinesRemoved: 23}
+
+	tests := []struct {
+		glyphs *GlyphSet
+		want   string
+	}{
+		{glyphs: UnicodeGlyphs, want: "✎ +156 -23"},
+		{glyphs: ASCIIGlyphs, want: "ed +156 -23"},
+	}
+
+	for _, tt := range tests {
+		if _, got, _ := EditsSegment(cost).Render(tt.glyphs); got != tt.want {
+			t.Errorf("Render(%s) = %q, want %q", tt.glyphs.Level, got, tt.want)
+		}
+	}
+
+	if _, got, _ := EditsSegment(parser.Cost{}).Render(UnicodeGlyphs); got != "" {
+		t.Errorf("Render() without edits = %q, want empty", got)
+	}
+}
+
+func TestHideGitDiff(t *testing.T) {
+	hook := &parser.StatusHook{
+		Model:   parser.Model{DisplayName: "Opus"},
+		Version: "2.0.0",
+		Cost:    parser.Cost{TotalLinesAdded: 156, TotalLinesRemoved: 23},
+	}
+	gitInfo := &metrics.GitInfo{IsGitRepo: true, Branch: "main", BranchDisplay: "main", Additions: 41, Deletions: 7, ChangesText: "(+41 -7)"}
+
+	opts := Options{Glyphs: UnicodeGlyphs, HideGitDiff: true, Extra: []Segment{EditsSegment(hook.Cost)}}
+	for name, newFormatter := range allFormatters {
+		got := ansi.Strip(newFormatter(opts).Format(hook, nil, gitInfo))
+		if strings.Contains(got, "41") || strings.Contains(got, "7 ") || !strings.Contains(got, "main") {
+			t.Errorf("%s: %q should show the branch without the git diff", name, got)
+		}
+		if !strings.Contains(got, "✎ +156 -23") {
+			t.Errorf("%s: %q is missing the session edits", name, got)
+		}
+	}
+}
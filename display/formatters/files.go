@@ -0,0 +1,33 @@
+package formatters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// formatGitFiles renders the number of files in each state with its icon, e.g. "●2 ~1 ?3",
+// or nothing for a clean working tree. Conflicts come first, since they block the next commit.
+func (o Options) formatGitFiles(gitInfo *metrics.GitInfo) string {
+	icons := o.glyphs().Icons
+	states := []struct {
+		count int
+		icon  string
+		style lipgloss.Style
+	}{
+		{gitInfo.Conflicts, icons.Conflict, conflictStyle},
+		{gitInfo.Staged, icons.Staged, greenStyle},
+		{gitInfo.Modified, icons.Modified, modifiedStyle},
+		{gitInfo.Untracked, icons.Untracked, grayStyle},
+	}
+
+	var parts []string
+	for _, state := range states {
+		if state.count > 0 {
+			parts = append(parts, state.style.Render(fmt.Sprintf("%s%d", state.icon, state.count)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
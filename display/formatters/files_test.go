@@ -0,0 +1,39 @@
+package formatters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestGitFilesSegment(t *testing.T) {
+	hook := &parser.StatusHook{Model: parser.Model{DisplayName: "Opus"}, Version: "2.0.0"}
+	gitInfo := &metrics.GitInfo{
+		IsGitRepo: true, Branch: "main", BranchDisplay: "main", ChangesText: "(no changes)",
+		Staged: 2, Modified: 1, Untracked: 3, Conflicts: 1,
+	}
+
+	tests := []struct {
+		glyphs *GlyphSet
+		want   string
+	}{
+		{glyphs: UnicodeGlyphs, want: "✖1 ●2 ~1 ?3"},
+		{glyphs: ASCIIGlyphs, want: "!1 =2 ~1 ?3"},
+	}
+
+	for _, tt := range tests {
+		for name, newFormatter := range allFormatters {
+			got := ansi.Strip(newFormatter(Options{Glyphs: tt.glyphs}).Format(hook, nil, gitInfo))
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("%s (%s): %q is missing the file states %q", name, tt.glyphs.Level, got, tt.want)
+			}
+		}
+	}
+
+	if got := (Options{Glyphs: UnicodeGlyphs}).formatGitFiles(&metrics.GitInfo{IsGitRepo: true}); got != "" {
+		t.Errorf("formatGitFiles() for a clean tree = %q, want empty", got)
+	}
+}
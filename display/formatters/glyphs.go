@@ -0,0 +1,186 @@
+package formatters
+
+import (
+	"os"
+	"strings"
+)
+
+// GlyphLevel selects which character repertoire formatters may emit
+type GlyphLevel string
+
+const (
+	GlyphsASCII    GlyphLevel = "ascii"
+	GlyphsUnicode  GlyphLevel = "unicode"
+	GlyphsNerdFont GlyphLevel = "nerdfont"
+)
+
+// GlyphLevels lists the selectable glyph levels in increasing order of font requirements
+var GlyphLevels = []GlyphLevel{GlyphsASCII, GlyphsUnicode, GlyphsNerdFont}
+
+// BarGlyphs holds the characters used to draw a progress bar
+type BarGlyphs struct {
+	Full    string
+	Empty   string
+	Partial []string // 1/8 increments starting at empty; nil disables fractional fill
+}
+
+// GlyphSet contains every character a formatter may emit beyond plain text
+type GlyphSet struct {
+	Level GlyphLevel
+
+	HorizontalBar BarGlyphs
+	VerticalBar   BarGlyphs
+	BarOpen       string // Wraps bars that would be unreadable without delimiters
+	BarClose      string
+
+	Separator     string // Vertical separator between segments
+	PathSeparator string // Between the project name and the directory inside it
+	Rule          string // Horizontal line above and below the status line
+
+	BoxTopLeft     string
+	BoxTopRight    string
+	BoxBottomLeft  string
+	BoxBottomRight string
+
+	Additions string // Git additions marker used by the nerd style
+	Deletions string // Git deletions marker used by the nerd style
+	ArrowUp   string // Git additions marker used by the compact style
+	ArrowDown string // Git deletions marker used by the compact style
+	Warning   string // Prefix of the marker shown in place of a broken segment
+
+	Icons IconSet
+
+	// IconsInAllStyles prefixes segments with icons even in styles that are text-only by default
+	IconsInAllStyles bool
+}
+
+// UnicodeGlyphs uses block elements and box drawing characters available in most modern fonts
+var UnicodeGlyphs = &GlyphSet{
+	Level:         GlyphsUnicode,
+	HorizontalBar: BarGlyphs{Full: fullBlock, Empty: emptyBlock, Partial: HorizontalBlocks},
+	VerticalBar:   BarGlyphs{Full: fullBlock, Empty: emptyBlock, Partial: VerticalBlocks},
+
+	Separator:     "│",
+	PathSeparator: "▸",
+	Rule:          "─",
+
+	BoxTopLeft:     "┌",
+	BoxTopRight:    "┐",
+	BoxBottomLeft:  "└",
+	BoxBottomRight: "┘",
+
+	Additions: "⇡",
+	Deletions: "⇣",
+	ArrowUp:   "↑",
+	ArrowDown: "↓",
+	Warning:   "⚠",
+
+	Icons: unicodeIcons,
+}
+
+// NerdFontGlyphs extends the unicode set with Nerd Font icons on every segment
+var NerdFontGlyphs = withOverrides(UnicodeGlyphs, func(g *GlyphSet) {
+	g.Level = GlyphsNerdFont
+	g.Icons = nerdFontIcons
+	g.IconsInAllStyles = true
+})
+
+// ASCIIGlyphs renders everything with 7-bit characters for limited fonts and consoles
+var ASCIIGlyphs = &GlyphSet{
+	Level:         GlyphsASCII,
+	HorizontalBar: BarGlyphs{Full: "#", Empty: "-"},
+	VerticalBar:   BarGlyphs{Full: "#", Empty: "-"},
+	BarOpen:       "[",
+	BarClose:      "]",
+
+	Separator:     "|",
+	PathSeparator: ">",
+	Rule:          "-",
+
+	BoxTopLeft:     "+",
+	BoxTopRight:    "+",
+	BoxBottomLeft:  "+",
+	BoxBottomRight: "+",
+
+	Additions: "+",
+	Deletions: "-",
+	ArrowUp:   "+",
+	ArrowDown: "-",
+	Warning:   "!",
+
+	Icons: asciiIcons,
+}
+
+// withOverrides copies base and applies modify to the copy
+func withOverrides(base *GlyphSet, modify func(g *GlyphSet)) *GlyphSet {
+	glyphs := *base
+	modify(&glyphs)
+	return &glyphs
+}
+
+// WrapBar surrounds a rendered bar with the set's delimiters
+func (g *GlyphSet) WrapBar(bar string) string {
+	return g.BarOpen + bar + g.BarClose
+}
+
+// GlyphSetFor returns the glyph set for a level, falling back to unicode
+func GlyphSetFor(level GlyphLevel) *GlyphSet {
+	switch level {
+	case GlyphsASCII:
+		return ASCIIGlyphs
+	case GlyphsNerdFont:
+		return NerdFontGlyphs
+	default:
+		return UnicodeGlyphs
+	}
+}
+
+// ResolveGlyphSet maps a user supplied level name to a glyph set.
+// "auto" and empty names are resolved from the environment.
+func ResolveGlyphSet(name string) *GlyphSet {
+	return ResolveGlyphSetWith(name, os.Getenv)
+}
+
+// ResolveGlyphSetWith is ResolveGlyphSet reading another process's environment,
+// as the daemon does for its clients
+func ResolveGlyphSetWith(name string, getenv func(string) string) *GlyphSet {
+	if name == "" || name == "auto" {
+		return GlyphSetFor(DetectGlyphLevelWith(getenv))
+	}
+	return GlyphSetFor(GlyphLevel(name))
+}
+
+// DetectGlyphLevel picks a glyph level from the TERM and locale environment variables.
+// Nerd Fonts cannot be detected, so the best automatic result is unicode.
+func DetectGlyphLevel() GlyphLevel {
+	return DetectGlyphLevelWith(os.Getenv)
+}
+
+// DetectGlyphLevelWith is DetectGlyphLevel with a custom environment lookup
+func DetectGlyphLevelWith(getenv func(string) string) GlyphLevel {
+	switch getenv("TERM") {
+	case "linux", "dumb", "vt100", "vt220", "cons25":
+		return GlyphsASCII
+	}
+
+	// The first non-empty variable wins, following POSIX locale precedence
+	locale := ""
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(key); value != "" {
+			locale = value
+			break
+		}
+	}
+
+	// No locale at all is typical on Windows, where modern terminals handle unicode
+	if locale == "" {
+		return GlyphsUnicode
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(locale, "-", ""))
+	if strings.Contains(normalized, "utf8") {
+		return GlyphsUnicode
+	}
+
+	return GlyphsASCII
+}
@@ -9,10 +9,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-const (
-	gradientTotalBlocks = 10
-	gradientSeparator   = " │ "
-)
+const gradientTotalBlocks = 10
 
 // Color thresholds for gradient bar
 var (
@@ -22,42 +19,64 @@ var (
 )
 
 // GradientFormatter implements a style with height-variable context bar and dynamic colors
-type GradientFormatter struct{}
+type GradientFormatter struct {
+	Options
+}
 
 // Format creates the status line with gradient-style context visualization
 func (f *GradientFormatter) Format(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string {
 	var segments []string
+	icons := f.glyphs().Icons
 
 	// Model name (compact, no "Model:" prefix)
-	segments = append(segments, modelStyle.Render(hook.Model.DisplayName))
+	segments = f.appendSegment(segments, "model", func() string {
+		return modelStyle.Render(f.label(icons.ModelIcon(hook.Model), hook.Model.DisplayName))
+	})
 
 	// Git info
-	if gitInfo != nil && gitInfo.IsGitRepo {
-		gitSegment := f.formatGitInfo(gitInfo)
-		segments = append(segments, gitSegment)
+	if gitInfo != nil && gitInfo.IsGitRepo || f.Failed["git-branch"] {
+		segments = f.appendSegment(segments, "git-branch", func() string {
+			return f.formatGitInfo(gitInfo)
+		})
+		segments = f.appendSegment(segments, "git-files", func() string {
+			return f.formatGitFiles(gitInfo)
+		})
+		segments = f.appendSegment(segments, "git-location", func() string {
+			return f.formatGitLocation(gitInfo)
+		})
 	}
 
 	// Output style (compact)
-	if hook.OutputStyle.Name != "" {
-		segments = append(segments, styleColor.Render(hook.OutputStyle.Name))
-	}
+	segments = f.appendSegment(segments, "output-style", func() string {
+		if hook.OutputStyle.Name == "" {
+			return ""
+		}
+		return styleColor.Render(f.label(icons.Style, hook.OutputStyle.Name))
+	})
 
 	// Version
-	versionSegment := fmt.Sprintf("v%s", hook.Version)
-	segments = append(segments, blueStyle.Render(versionSegment))
+	segments = f.appendSegment(segments, "version", func() string {
+		versionSegment := f.label(icons.Version, fmt.Sprintf("v%s", hook.Version))
+		return blueStyle.Render(versionSegment)
+	})
+
+	// Optional segments enabled in the config
+	segments = f.appendExtra(segments, false)
 
 	// Context visualization with gradient bar
-	if tokenMetrics != nil && tokenMetrics.ContextPercentage > 0 {
-		contextSegment := f.formatGradientBar(tokenMetrics)
-		segments = append(segments, contextSegment)
-	}
+	segments = f.appendSegment(segments, "context", func() string {
+		if tokenMetrics == nil || tokenMetrics.ContextPercentage <= 0 {
+			return ""
+		}
+		return f.formatGradientBar(tokenMetrics)
+	})
 
 	// Join with vertical bar separator
-	statusLine := strings.Join(segments, grayStyle.Render(gradientSeparator))
+	separator := " " + f.glyphs().Separator + " "
+	statusLine := strings.Join(segments, grayStyle.Render(separator))
 
 	// Add subtle horizontal lines for visual breathing room
-	width := lipgloss.Width(statusLine)
-	line := lineStyle.Render(strings.Repeat("─", width))
+	line := f.rule(lipgloss.Width(statusLine))
 
 	return line + "\n" + statusLine + "\n" + line
 }
@@ -77,9 +96,10 @@ func (f *GradientFormatter) formatGradientBar(tokenMetrics *metrics.TokenMetrics
 		barStyle = gradientGreen
 	}
 
-	bar := RenderProgressBar(percentage, gradientTotalBlocks, VerticalBlocks, barStyle, dimStyle)
+	glyphs := f.glyphs()
+	bar := glyphs.WrapBar(RenderProgressBar(percentage, gradientTotalBlocks, glyphs.VerticalBar, barStyle, dimStyle))
 
-	return fmt.Sprintf("%s %d%%", bar, int(percentage))
+	return f.label(glyphs.Icons.Context, fmt.Sprintf("%s %d%%", bar, int(percentage)))
 }
 
 // formatGitInfo formats git branch and changes
@@ -88,10 +108,10 @@ func (f *GradientFormatter) formatGitInfo(gitInfo *metrics.GitInfo) string {
 		return ""
 	}
 
-	branch := branchStyle.Render(gitInfo.BranchDisplay)
+	branch := f.branchLabel(gitInfo, f.label)
 
 	// Format changes if present
-	if gitInfo.Additions > 0 || gitInfo.Deletions > 0 {
+	if !f.HideGitDiff && (gitInfo.Additions > 0 || gitInfo.Deletions > 0) {
 		changes := fmt.Sprintf("(+%d/-%d)", gitInfo.Additions, gitInfo.Deletions)
 		return branch + " " + grayStyle.Render(changes)
 	}
@@ -0,0 +1,184 @@
+package formatters
+
+import (
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// IconSet holds the icon shown in front of each segment
+type IconSet struct {
+	Model         string            // Fallback when no model family matches
+	ModelFamilies map[string]string // Keyed by lowercase family name found in the model ID or display name
+
+	Style   string
+	Version string
+	Context string
+
+	Branch    string
+	Tag       string
+	Detached  string
+	Worktree  string
+	Submodule string
+	Folder    string
+
+	// File states
+	Added     string
+	Modified  string
+	Deleted   string
+	Untracked string
+	Staged    string
+	Conflict  string
+
+	Languages map[string]string // Keyed by lowercase language name
+	Infra     map[string]string // Keyed by infrastructure segment name: kube, aws, gcloud, docker
+
+	Edit      string
+	Clock     string
+	Hourglass string
+	Dollar    string
+}
+
+// modelFamilyOrder fixes the match order so "claude-opus" resolves to opus rather than claude
+var modelFamilyOrder = []string{
+	"opus", "sonnet", "haiku", "claude",
+	"gpt", "openai", "gemini", "llama", "mistral", "deepseek", "qwen",
+}
+
+var unicodeIcons = IconSet{
+	Model:   "❋",
+	Style:   "⎔",
+	Version: "⌘",
+	Context: "◐",
+
+	Branch:    "⎇",
+	Tag:       "⚑",
+	Detached:  "⌀",
+	Worktree:  "⑂",
+	Submodule: "⧉",
+	Folder:    "⌂",
+
+	Added:     "+",
+	Modified:  "~",
+	Deleted:   "-",
+	Untracked: "?",
+	Staged:    "●",
+	Conflict:  "✖",
+
+	Edit:      "✎",
+	Clock:     "◷",
+	Hourglass: "⧗",
+	Dollar:    "$",
+}
+
+var nerdFontIcons = IconSet{
+	Model: "\U000f06a9", // nf-md-robot
+	ModelFamilies: map[string]string{
+		"opus":     "\U000f075a", // nf-md-music
+		"sonnet":   "\U000f06d3", // nf-md-feather
+		"haiku":    "\U000f032a", // nf-md-leaf
+		"claude":   "\U000f06a9", // nf-md-robot
+		"gpt":      "\U000f0674", // nf-md-creation
+		"openai":   "\U000f0674", // nf-md-creation
+		"gemini":   "\U000f0ae2", // nf-md-star_four_points
+		"llama":    "\U000f09d1", // nf-md-brain
+		"mistral":  "\U000f059f", // nf-md-weather_windy
+		"deepseek": "\U000f0349", // nf-md-magnify
+		"qwen":     "\U000f09d1", // nf-md-brain
+	},
+
+	Style:   "\U000f03d8", // nf-md-palette
+	Version: "\uf454",     // nf-oct-versions
+	Context: "\U000f029a", // nf-md-gauge
+
+	Branch:    "\ue725", // nf-dev-git_branch
+	Tag:       "\uf412", // nf-oct-tag
+	Detached:  "\uf417", // nf-oct-git_commit
+	Worktree:  "\uf402", // nf-oct-repo_forked
+	Submodule: "\uf414", // nf-oct-file_submodule
+	Folder:    "\uf07c", // nf-fa-folder_open
+
+	Added:     "\uf457", // nf-oct-diff_added
+	Modified:  "\uf459", // nf-oct-diff_modified
+	Deleted:   "\uf458", // nf-oct-diff_removed
+	Untracked: "\uf128", // nf-fa-question
+	Staged:    "\uf00c", // nf-fa-check
+	Conflict:  "\uf071", // nf-fa-warning
+
+	Languages: map[string]string{
+		"go":         "\ue627", // nf-seti-go
+		"node":       "\ue718", // nf-dev-nodejs_small
+		"javascript": "\ue74e", // nf-dev-javascript
+		"typescript": "\ue628", // nf-seti-typescript
+		"python":     "\ue73c", // nf-dev-python
+		"rust":       "\ue7a8", // nf-dev-rust
+		"ruby":       "\ue739", // nf-dev-ruby
+		"java":       "\ue738", // nf-dev-java
+	},
+	Infra: map[string]string{
+		"kube":   "\U000f10fe", // nf-md-kubernetes
+		"aws":    "\uf375",     // nf-fa-aws
+		"gcloud": "\U000f11f6", // nf-md-google_cloud
+		"docker": "\uf308",     // nf-linux-docker
+	},
+
+	Edit:      "\U000f03eb", // nf-md-pencil
+	Clock:     "\U000f0150", // nf-md-clock_outline
+	Hourglass: "\U000f051f", // nf-md-timer_sand
+	Dollar:    "\U000f01c1", // nf-md-currency_usd
+}
+
+var asciiIcons = IconSet{
+	Model:   "*",
+	Style:   "~",
+	Version: "v",
+	Context: "ctx",
+
+	Branch:    "@",
+	Tag:       "#",
+	Detached:  "!",
+	Worktree:  "wt",
+	Submodule: "sub",
+	Folder:    "dir",
+
+	Added:     "+",
+	Modified:  "~",
+	Deleted:   "-",
+	Untracked: "?",
+	Staged:    "=",
+	Conflict:  "!",
+
+	Edit:      "ed",
+	Clock:     "t",
+	Hourglass: "api",
+	Dollar:    "$",
+}
+
+// ModelIcon picks the icon for the model's family, falling back to the generic model icon
+func (i IconSet) ModelIcon(model parser.Model) string {
+	name := strings.ToLower(model.ID + " " + model.DisplayName)
+	for _, family := range modelFamilyOrder {
+		if icon, ok := i.ModelFamilies[family]; ok && strings.Contains(name, family) {
+			return icon
+		}
+	}
+	return i.Model
+}
+
+// GitRefIcon picks the branch, tag or detached icon for the checked out ref
+func (i IconSet) GitRefIcon(gitInfo *metrics.GitInfo) string {
+	switch {
+	case gitInfo.Detached && gitInfo.Tag != "":
+		return i.Tag
+	case gitInfo.Detached:
+		return i.Detached
+	default:
+		return i.Branch
+	}
+}
+
+// LanguageIcon returns the icon for a language, or an empty string when none is known
+func (i IconSet) LanguageIcon(language string) string {
+	return i.Languages[strings.ToLower(language)]
+}
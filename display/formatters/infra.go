@@ -0,0 +1,34 @@
+package formatters
+
+import (
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// InfraSegment shows the context a tool would act on, e.g. "kube prod-eu (payments)".
+// The tool name gives way to its icon when the glyph set has one. dangerous renders the
+// segment in bold red, for contexts matching the user's danger patterns.
+func InfraSegment(name string, context *metrics.InfraContext, dangerous bool) Segment {
+	return Segment{
+		Name: name,
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if context == nil {
+				return "", "", infraStyle
+			}
+
+			text := context.Name
+			if context.Detail != "" {
+				text += " (" + context.Detail + ")"
+			}
+			style := infraStyle
+			if dangerous {
+				style = infraDangerStyle
+			}
+
+			if icon := glyphs.Icons.Infra[name]; icon != "" && glyphs.IconsInAllStyles {
+				return icon, text, style
+			}
+			return "", name + " " + text, style
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package formatters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+)
+
+// gitLocation describes where the checkout sits, e.g. "wt feature-x (app)" for a linked
+// worktree, "sub app/libs/ui" inside a submodule and "sub 2 dirty" for dirty submodules.
+// Empty for a plain checkout.
+func (o Options) gitLocation(gitInfo *metrics.GitInfo) string {
+	icons := o.glyphs().Icons
+	var parts []string
+
+	if gitInfo.Worktree != "" {
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", o.iconOr(icons.Worktree, "wt"), gitInfo.Worktree, gitInfo.MainRepo))
+	}
+	if gitInfo.Superproject != "" {
+		parts = append(parts, fmt.Sprintf("%s %s/%s", o.iconOr(icons.Submodule, "sub"), gitInfo.Superproject, gitInfo.Submodule))
+	}
+	if gitInfo.DirtySubmodules > 0 {
+		parts = append(parts, fmt.Sprintf("%s %d dirty", o.iconOr(icons.Submodule, "sub"), gitInfo.DirtySubmodules))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatGitLocation renders gitLocation in the location color, or nothing for a plain checkout
+func (o Options) formatGitLocation(gitInfo *metrics.GitInfo) string {
+	location := o.gitLocation(gitInfo)
+	if location == "" {
+		return ""
+	}
+	return locationStyle.Render(location)
+}
+
+// branchLabel renders the checked out ref, with label placing the icon as the style does.
+// Changes on a protected branch switch to the warning style with a warning sign in every
+// glyph set, since it is the one hint that must not be missed.
+func (o Options) branchLabel(gitInfo *metrics.GitInfo, label func(icon, text string) string) string {
+	if o.ProtectedBranch {
+		return protectedBranchStyle.Render(o.glyphs().Warning + " " + gitInfo.BranchDisplay)
+	}
+	return branchStyle.Render(label(o.glyphs().Icons.GitRefIcon(gitInfo), gitInfo.BranchDisplay))
+}
@@ -10,48 +10,72 @@ import (
 )
 
 // MinimalFormatter implements an ultra-compact style with no decorations
-type MinimalFormatter struct{}
+type MinimalFormatter struct {
+	Options
+}
 
 // Format creates a compact single-line status line
 func (f *MinimalFormatter) Format(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string {
 	var parts []string
+	icons := f.glyphs().Icons
 
 	// Model name (always present)
-	parts = append(parts, modelStyle.Render(hook.Model.DisplayName))
+	parts = f.appendSegment(parts, "model", func() string {
+		return modelStyle.Render(f.label(icons.ModelIcon(hook.Model), hook.Model.DisplayName))
+	})
 
 	// Git branch and changes
-	if gitInfo != nil && gitInfo.IsGitRepo {
-		parts = append(parts, branchStyle.Render(gitInfo.BranchDisplay))
+	if gitInfo != nil && gitInfo.IsGitRepo || f.Failed["git-branch"] {
+		parts = f.appendSegment(parts, "git-branch", func() string {
+			return f.branchLabel(gitInfo, f.label)
+		})
 
 		// Git changes in compact format: +156-23
-		if gitInfo.Additions > 0 || gitInfo.Deletions > 0 {
-			coloredChanges := greenStyle.Render(fmt.Sprintf("+%d", gitInfo.Additions)) + redStyle.Render(fmt.Sprintf("-%d", gitInfo.Deletions))
-			parts = append(parts, coloredChanges)
-		}
+		parts = f.appendSegment(parts, "git-changes", func() string {
+			if f.HideGitDiff || gitInfo.Additions == 0 && gitInfo.Deletions == 0 {
+				return ""
+			}
+			return greenStyle.Render(fmt.Sprintf("+%d", gitInfo.Additions)) + redStyle.Render(fmt.Sprintf("-%d", gitInfo.Deletions))
+		})
+		parts = f.appendSegment(parts, "git-files", func() string {
+			return f.formatGitFiles(gitInfo)
+		})
+		parts = f.appendSegment(parts, "git-location", func() string {
+			return f.formatGitLocation(gitInfo)
+		})
 	} else {
 		parts = append(parts, grayStyle.Render("(no git)"))
 	}
 
 	// Output style (only if present)
-	if hook.OutputStyle.Name != "" {
-		parts = append(parts, styleColor.Render(hook.OutputStyle.Name))
-	}
+	parts = f.appendSegment(parts, "output-style", func() string {
+		if hook.OutputStyle.Name == "" {
+			return ""
+		}
+		return styleColor.Render(f.label(icons.Style, hook.OutputStyle.Name))
+	})
 
 	// Version (always present)
-	parts = append(parts, blueStyle.Render(hook.Version))
+	parts = f.appendSegment(parts, "version", func() string {
+		return blueStyle.Render(f.label(icons.Version, hook.Version))
+	})
+
+	// Optional segments enabled in the config
+	parts = f.appendExtra(parts, false)
 
 	// Context percentage (only if available)
-	if tokenMetrics != nil && tokenMetrics.ContextPercentage > 0 {
-		ctxPart := fmt.Sprintf("%d%%", int(tokenMetrics.ContextPercentage))
-		parts = append(parts, ctxPart)
-	}
+	parts = f.appendSegment(parts, "context", func() string {
+		if tokenMetrics == nil || tokenMetrics.ContextPercentage <= 0 {
+			return ""
+		}
+		return f.label(icons.Context, fmt.Sprintf("%d%%", int(tokenMetrics.ContextPercentage)))
+	})
 
 	// Join with single space
 	statusLine := strings.Join(parts, " ")
 
 	// Add subtle horizontal lines for visual breathing room
-	width := lipgloss.Width(statusLine)
-	line := lineStyle.Render(strings.Repeat("─", width))
+	line := f.rule(lipgloss.Width(statusLine))
 
 	return line + "\n" + statusLine + "\n" + line
 }
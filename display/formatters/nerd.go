@@ -9,86 +9,107 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-const (
-	nerdTotalBlocks = 10
-	nerdFilledBlock = "█"
-	nerdEmptyBlock  = "░"
-)
+const nerdTotalBlocks = 10
 
 // NerdFormatter implements a technical panel style with borders and absolute token counts
-type NerdFormatter struct{}
+type NerdFormatter struct {
+	Options
+}
 
 // Format creates a bordered panel with detailed token metrics
 func (f *NerdFormatter) Format(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string {
 	var segments []string
+	glyphs := f.glyphs()
 
 	// Model name
-	segments = append(segments, modelStyle.Render(hook.Model.DisplayName))
+	segments = f.appendSegment(segments, "model", func() string {
+		return modelStyle.Render(f.label(glyphs.Icons.ModelIcon(hook.Model), hook.Model.DisplayName))
+	})
 
 	// Git branch and changes
-	if gitInfo != nil && gitInfo.IsGitRepo {
-		gitSegment := fmt.Sprintf("%s %s%d %s%d",
-			branchStyle.Render(gitInfo.BranchDisplay),
-			greenStyle.Render("⇡"),
-			gitInfo.Additions,
-			redStyle.Render("⇣"),
-			gitInfo.Deletions)
-		segments = append(segments, gitSegment)
+	if gitInfo != nil && gitInfo.IsGitRepo || f.Failed["git-branch"] {
+		segments = f.appendSegment(segments, "git-branch", func() string {
+			branch := f.branchLabel(gitInfo, f.label)
+			if f.HideGitDiff {
+				return branch
+			}
+			return fmt.Sprintf("%s %s%d %s%d",
+				branch,
+				greenStyle.Render(glyphs.Additions),
+				gitInfo.Additions,
+				redStyle.Render(glyphs.Deletions),
+				gitInfo.Deletions)
+		})
+		segments = f.appendSegment(segments, "git-files", func() string {
+			return f.formatGitFiles(gitInfo)
+		})
+		segments = f.appendSegment(segments, "git-location", func() string {
+			return f.formatGitLocation(gitInfo)
+		})
 	}
 
 	// Output style (if present)
-	if hook.OutputStyle.Name != "" {
-		segments = append(segments, styleColor.Render(hook.OutputStyle.Name))
-	}
+	segments = f.appendSegment(segments, "output-style", func() string {
+		if hook.OutputStyle.Name == "" {
+			return ""
+		}
+		return styleColor.Render(f.label(glyphs.Icons.Style, hook.OutputStyle.Name))
+	})
 
 	// Version
-	versionSegment := blueStyle.Render("v" + hook.Version)
-	segments = append(segments, versionSegment)
+	segments = f.appendSegment(segments, "version", func() string {
+		return blueStyle.Render(f.label(glyphs.Icons.Version, "v"+hook.Version))
+	})
+
+	// Optional segments enabled in the config
+	segments = f.appendExtra(segments, false)
 
 	// Context with absolute tokens
-	if tokenMetrics != nil && tokenMetrics.ContextPercentage > 0 {
+	segments = f.appendSegment(segments, "context", func() string {
+		if tokenMetrics == nil || tokenMetrics.ContextPercentage <= 0 {
+			return ""
+		}
+
 		currentTokens := tokenMetrics.ContextLength
 		maxTokens := tokenMetrics.ContextWindowSize
 		bar := f.formatContextBar(tokenMetrics.ContextPercentage)
 
-		ctxSegment := fmt.Sprintf("CTX: %s/%s (%d%%) %s",
-			f.formatTokens(currentTokens),
-			f.formatTokens(maxTokens),
+		return f.label(glyphs.Icons.Context, fmt.Sprintf("CTX: %s/%s (%d%%) %s",
+			HumanizeTokens(currentTokens),
+			HumanizeTokens(maxTokens),
 			int(tokenMetrics.ContextPercentage),
-			bar)
-		segments = append(segments, ctxSegment)
-	}
+			bar))
+	})
 
 	// Join segments with box separator
-	content := strings.Join(segments, grayStyle.Render(" │ "))
+	content := strings.Join(segments, grayStyle.Render(" "+glyphs.Separator+" "))
 
 	// Calculate width using lipgloss (strips ANSI codes)
 	contentWidth := lipgloss.Width(content)
 	totalWidth := contentWidth + 4 // +4 for "│ " prefix and " │" suffix
 
 	// Build bordered output
-	topBorder := grayStyle.Render("┌" + strings.Repeat("─", totalWidth) + "┐")
-	middle := grayStyle.Render("│ ") + content + grayStyle.Render(" │")
-	bottomBorder := grayStyle.Render("└" + strings.Repeat("─", totalWidth) + "┘")
+	border := strings.Repeat(glyphs.Rule, totalWidth)
+	topBorder := grayStyle.Render(glyphs.BoxTopLeft + border + glyphs.BoxTopRight)
+	middle := grayStyle.Render(glyphs.Separator+" ") + content + grayStyle.Render(" "+glyphs.Separator)
+	bottomBorder := grayStyle.Render(glyphs.BoxBottomLeft + border + glyphs.BoxBottomRight)
 
 	return topBorder + "\n" + middle + "\n" + bottomBorder
 }
 
 // formatContextBar creates a 10-block context visualization
 func (f *NerdFormatter) formatContextBar(percentage float64) string {
-	filled := int(percentage / 10)
-	if filled > nerdTotalBlocks {
-		filled = nerdTotalBlocks
-	}
+	filled := int(clampPercentage(percentage) / 10)
 
-	filledBar := whiteStyle.Render(strings.Repeat(nerdFilledBlock, filled))
-	emptyBar := dimStyle.Render(strings.Repeat(nerdEmptyBlock, nerdTotalBlocks-filled))
+	bar := f.glyphs().HorizontalBar
+	filledBar := whiteStyle.Render(strings.Repeat(bar.Full, filled))
+	emptyBar := dimStyle.Render(strings.Repeat(bar.Empty, nerdTotalBlocks-filled))
 
-	return filledBar + emptyBar
+	return f.glyphs().WrapBar(filledBar + emptyBar)
 }
 
-// formatTokens formats token count in human-readable format (k, M, etc.)
-func (f *NerdFormatter) formatTokens(tokens int) string {
+// HumanizeTokens formats a token count in human-readable form, e.g. "15.5k" or "1.2M"
+func HumanizeTokens(tokens int) string {
 	if tokens >= 1000000 {
 		return fmt.Sprintf("%.1fM", float64(tokens)/1000000)
 	}
@@ -0,0 +1,105 @@
+package formatters
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Options carries presentation settings shared by every formatter
+type Options struct {
+	Glyphs *GlyphSet
+
+	// Failed marks segments whose data source failed; they render as an error marker
+	Failed map[string]bool
+
+	// OnPanic is called with the stack trace when rendering a segment panics
+	OnPanic func(segment string, recovered any, stack []byte)
+
+	// Extra lists the optional segments enabled in the config, in display order
+	Extra []Segment
+
+	// ProtectedBranch is set while the working tree has changes on a protected branch
+	ProtectedBranch bool
+
+	// HideGitDiff drops the working tree diff from the git segments, for when the edits
+	// segment shows the lines Claude changed instead
+	HideGitDiff bool
+
+	// Overrides restyles or hides segments by name, following the config's styling rules
+	Overrides map[string]Override
+}
+
+// glyphs returns the configured glyph set, defaulting to unicode
+func (o Options) glyphs() *GlyphSet {
+	if o.Glyphs == nil {
+		return UnicodeGlyphs
+	}
+	return o.Glyphs
+}
+
+// rule renders the subtle horizontal line placed above and below the status line
+func (o Options) rule(width int) string {
+	return lineStyle.Render(strings.Repeat(o.glyphs().Rule, width))
+}
+
+// label prefixes text with an icon when the glyph set asks for icons in every style
+func (o Options) label(icon, text string) string {
+	if !o.glyphs().IconsInAllStyles || icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
+
+// iconOr returns the icon when the glyph set asks for icons in every style, otherwise the fallback
+func (o Options) iconOr(icon, fallback string) string {
+	if !o.glyphs().IconsInAllStyles || icon == "" {
+		return fallback
+	}
+	return icon
+}
+
+// segment renders one segment in isolation: a failed data source or a panic while
+// rendering yields a compact error marker instead of taking down the whole status line
+func (o Options) segment(name string, render func() string) (result string) {
+	if o.Failed[name] {
+		return o.ErrorMarker(name)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if o.OnPanic != nil {
+				o.OnPanic(name, recovered, debug.Stack())
+			}
+			result = o.ErrorMarker(name)
+		}
+	}()
+
+	return render()
+}
+
+// appendSegment renders a segment and appends it unless it is empty or hidden by a rule.
+// Identical adjacent error markers collapse, so a failed git source shows "⚠git" once.
+func (o Options) appendSegment(segments []string, name string, render func() string) []string {
+	override := o.Overrides[name]
+	if override.Hidden {
+		return segments
+	}
+
+	rendered := o.segment(name, render)
+	if rendered == "" {
+		return segments
+	}
+	if rendered == o.ErrorMarker(name) {
+		if len(segments) > 0 && segments[len(segments)-1] == rendered {
+			return segments
+		}
+		return append(segments, rendered)
+	}
+	return append(segments, o.applyOverride(rendered, override))
+}
+
+// ErrorMarker renders the marker shown in place of a broken segment, e.g. "⚠git" for "git-branch"
+func (o Options) ErrorMarker(name string) string {
+	short, _, _ := strings.Cut(name, "-")
+	return errorStyle.Render(o.glyphs().Warning + short)
+}
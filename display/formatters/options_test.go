@@ -0,0 +1,64 @@
+package formatters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// formatter mirrors display.StatusLineFormatter, which this package cannot import
+type formatter interface {
+	Format(hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) string
+}
+
+var allFormatters = map[string]func(Options) formatter{
+	"classic":  func(o Options) formatter { return &ClassicFormatter{o} },
+	"gradient": func(o Options) formatter { return &GradientFormatter{o} },
+	"compact":  func(o Options) formatter { return &CompactFormatter{o} },
+	"minimal":  func(o Options) formatter { return &MinimalFormatter{o} },
+	"nerd":     func(o Options) formatter { return &NerdFormatter{o} },
+}
+
+func TestSegmentPanicIsIsolated(t *testing.T) {
+	// A nil hook makes every hook-backed segment panic; the rest must still render
+	tokenMetrics := &metrics.TokenMetrics{ContextLength: 50000, ContextPercentage: 25, ContextWindowSize: 200000}
+	gitInfo := &metrics.GitInfo{IsGitRepo: true, Branch: "main", BranchDisplay: "main"}
+
+	for name, newFormatter := range allFormatters {
+		t.Run(name, func(t *testing.T) {
+			var panicked []string
+			opts := Options{
+				Glyphs:  ASCIIGlyphs,
+				OnPanic: func(segment string, _ any, _ []byte) { panicked = append(panicked, segment) },
+			}
+
+			got := newFormatter(opts).Format(nil, tokenMetrics, gitInfo)
+
+			for _, marker := range []string{"!model", "!version"} {
+				if !strings.Contains(got, marker) {
+					t.Errorf("output %q is missing marker %q", got, marker)
+				}
+			}
+			if !strings.Contains(got, "main") || !strings.Contains(got, "25%") {
+				t.Errorf("output %q lost healthy segments", got)
+			}
+			if len(panicked) == 0 {
+				t.Error("OnPanic was not called")
+			}
+		})
+	}
+}
+
+func TestFailedSourceRendersMarkerOnce(t *testing.T) {
+	hook := &parser.StatusHook{Model: parser.Model{DisplayName: "Opus"}, Version: "2.0.0"}
+	opts := Options{Glyphs: ASCIIGlyphs, Failed: map[string]bool{"git-branch": true, "git-changes": true}}
+
+	for name, newFormatter := range allFormatters {
+		got := newFormatter(opts).Format(hook, nil, nil)
+		if count := strings.Count(got, "!git"); count != 1 {
+			t.Errorf("%s: output %q has %d git markers, want 1", name, got, count)
+		}
+	}
+}
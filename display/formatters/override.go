@@ -0,0 +1,142 @@
+package formatters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Override changes how one segment renders, as decided by the config's styling rules
+type Override struct {
+	Hidden bool
+	Style  *lipgloss.Style // Replaces the segment's own colors and attributes
+	Icon   string          // Replaces the segment's icon, or is prepended when it has none
+}
+
+// colorNames maps color names to ANSI colors, so they follow the terminal theme
+var colorNames = map[string]string{
+	"black": "0", "red": "1", "green": "2", "yellow": "3",
+	"blue": "4", "magenta": "5", "cyan": "6", "white": "7",
+	"gray": "8", "grey": "8", "orange": "208",
+}
+
+// ParseStyle parses a style such as "bold red blink" or "reverse 214 on #303030":
+// attributes, a foreground color, and a background color after "on". Colors are names,
+// ANSI 256 color numbers or hex colors.
+func ParseStyle(spec string) (lipgloss.Style, error) {
+	style := lipgloss.NewStyle()
+	words := strings.Fields(strings.ToLower(spec))
+	for i := 0; i < len(words); i++ {
+		switch word := words[i]; word {
+		case "bold":
+			style = style.Bold(true)
+		case "faint", "dim":
+			style = style.Faint(true)
+		case "italic":
+			style = style.Italic(true)
+		case "underline":
+			style = style.Underline(true)
+		case "blink":
+			style = style.Blink(true)
+		case "reverse":
+			style = style.Reverse(true)
+		case "strikethrough":
+			style = style.Strikethrough(true)
+		case "on":
+			if i+1 == len(words) {
+				return style, fmt.Errorf("style %q: missing color after \"on\"", spec)
+			}
+			i++
+			color, err := parseColor(words[i])
+			if err != nil {
+				return style, fmt.Errorf("style %q: %w", spec, err)
+			}
+			style = style.Background(color)
+		default:
+			color, err := parseColor(word)
+			if err != nil {
+				return style, fmt.Errorf("style %q: %w", spec, err)
+			}
+			style = style.Foreground(color)
+		}
+	}
+	return style, nil
+}
+
+// parseColor parses a color name, ANSI 256 color number or hex color
+func parseColor(word string) (lipgloss.Color, error) {
+	if code, ok := colorNames[word]; ok {
+		return lipgloss.Color(code), nil
+	}
+	if number, err := strconv.Atoi(word); err == nil && number >= 0 && number <= 255 {
+		return lipgloss.Color(word), nil
+	}
+	if len(word) == 7 && word[0] == '#' {
+		if _, err := strconv.ParseUint(word[1:], 16, 32); err == nil {
+			return lipgloss.Color(word), nil
+		}
+	}
+	return "", fmt.Errorf("unknown attribute or color %q", word)
+}
+
+// applyOverride restyles a rendered segment. A new style replaces every color inside the
+// segment, since they were already rendered into escape sequences.
+func (o Options) applyOverride(rendered string, override Override) string {
+	if override.Style == nil && override.Icon == "" {
+		return rendered
+	}
+
+	plain := ansi.Strip(rendered)
+	if override.Icon != "" {
+		plain = override.Icon + " " + o.glyphs().Icons.trimIcon(plain)
+	}
+	if override.Style == nil {
+		// Keep the color the segment starts with
+		if sgr := leadingSGR(rendered); sgr != "" {
+			return sgr + plain + "\x1b[0m"
+		}
+		return plain
+	}
+	return override.Style.Render(plain)
+}
+
+// leadingSGR returns the escape sequence a rendered string starts with, if any
+func leadingSGR(rendered string) string {
+	if !strings.HasPrefix(rendered, "\x1b[") {
+		return ""
+	}
+	end := strings.IndexByte(rendered, 'm')
+	if end < 0 {
+		return ""
+	}
+	return rendered[:end+1]
+}
+
+// trimIcon removes the icon a segment starts with, if any
+func (i IconSet) trimIcon(text string) string {
+	for _, icon := range i.all() {
+		if rest, ok := strings.CutPrefix(text, icon+" "); ok && icon != "" {
+			return rest
+		}
+	}
+	return text
+}
+
+// all lists every icon in the set
+func (i IconSet) all() []string {
+	icons := []string{
+		i.Model, i.Style, i.Version, i.Context,
+		i.Branch, i.Tag, i.Detached, i.Worktree, i.Submodule, i.Folder,
+		i.Added, i.Modified, i.Deleted, i.Untracked, i.Staged, i.Conflict,
+		i.Edit, i.Clock, i.Hourglass, i.Dollar,
+	}
+	for _, group := range []map[string]string{i.ModelFamilies, i.Languages, i.Infra} {
+		for _, icon := range group {
+			icons = append(icons, icon)
+		}
+	}
+	return icons
+}
@@ -0,0 +1,90 @@
+package formatters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseStyle(t *testing.T) {
+	style, err := ParseStyle("bold red blink on #303030")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !style.GetBold() || !style.GetBlink() || style.GetForeground() == nil || style.GetBackground() == nil {
+		t.Errorf("ParseStyle() = %+v, missing attributes", style)
+	}
+
+	for _, spec := range []string{"bold sparkly", "256", "#12345", "red on"} {
+		if _, err := ParseStyle(spec); err == nil {
+			t.Errorf("ParseStyle(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	hook := &parser.StatusHook{Model: parser.Model{DisplayName: "Opus"}, Version: "2.0.0"}
+	reverse, _ := ParseStyle("reverse")
+
+	tests := []struct {
+		name     string
+		override Override
+		check    func(t *testing.T, version string)
+	}{
+		{
+			name:     "hidden",
+			override: Override{Hidden: true},
+			check: func(t *testing.T, version string) {
+				if version != "" {
+					t.Errorf("hidden segment rendered as %q", version)
+				}
+			},
+		},
+		{
+			name:     "style",
+			override: Override{Style: &reverse},
+			check: func(t *testing.T, version string) {
+				if want := reverse.Render("⌘ 2.0.0"); version != want {
+					t.Errorf("restyled segment = %q, want %q", version, want)
+				}
+			},
+		},
+		{
+			name:     "icon replaces the segment's own",
+			override: Override{Icon: "!"},
+			check: func(t *testing.T, version string) {
+				if plain := ansi.Strip(version); plain != "! 2.0.0" {
+					t.Errorf("segment = %q, want %q", plain, "! 2.0.0")
+				}
+				if !strings.HasPrefix(version, "\x1b[") {
+					t.Errorf("segment %q lost its color", version)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Glyphs: UnicodeGlyphs, Overrides: map[string]Override{"version": tt.override}}
+			got := opts.appendSegment(nil, "version", func() string {
+				return blueStyle.Render(UnicodeGlyphs.Icons.Version + " 2.0.0")
+			})
+
+			version := ""
+			if len(got) > 0 {
+				version = got[0]
+			}
+			tt.check(t, version)
+		})
+	}
+
+	// Every style goes through the overrides
+	opts := Options{Glyphs: ASCIIGlyphs, Overrides: map[string]Override{"version": {Hidden: true}}}
+	for name, newFormatter := range allFormatters {
+		if got := newFormatter(opts).Format(hook, nil, nil); strings.Contains(got, "2.0.0") {
+			t.Errorf("%s: hidden version still rendered in %q", name, got)
+		}
+	}
+}
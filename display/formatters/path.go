@@ -0,0 +1,86 @@
+package formatters
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pathMaxLength is the length above which path components are abbreviated
+const pathMaxLength = 30
+
+// PathSegment shows the project name and the working directory relative to it, e.g.
+// "api ▸ internal/auth". Outside the project it shows the full working directory in the
+// warning style instead, since edits there land in another tree. home is replaced by "~".
+func PathSegment(workspace parser.Workspace, home string) Segment {
+	return Segment{
+		Name: "path",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			cwd, project := workspace.CurrentDir, workspace.ProjectDir
+			if cwd == "" {
+				return "", "", pathStyle
+			}
+
+			if project != "" {
+				rel, err := filepath.Rel(project, cwd)
+				switch {
+				case err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)):
+					return glyphs.Warning, ShortenPath(TildePath(cwd, home), pathMaxLength), pathWarningStyle
+				case rel == ".":
+					return glyphs.Icons.Folder, filepath.Base(project), pathStyle
+				default:
+					name := filepath.Base(project)
+					return glyphs.Icons.Folder, name + " " + glyphs.PathSeparator + " " + ShortenPath(filepath.ToSlash(rel), pathMaxLength-len(name)), pathStyle
+				}
+			}
+
+			return glyphs.Icons.Folder, ShortenPath(TildePath(cwd, home), pathMaxLength), pathStyle
+		},
+	}
+}
+
+// TildePath replaces a leading home directory with "~" and uses forward slashes
+func TildePath(path, home string) string {
+	if home != "" {
+		if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			if rel == "." {
+				return "~"
+			}
+			return "~/" + filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// ShortenPath abbreviates a slash separated path the way fish does, from the left, until it
+// fits maxLength: "~/projects/api/internal/auth" becomes "~/p/a/internal/auth".
+// The last component is never abbreviated, and hidden directories keep their dot (".config" to ".c").
+func ShortenPath(path string, maxLength int) string {
+	components := strings.Split(path, "/")
+
+	length := utf8.RuneCountInString(path)
+	for i := 0; i < len(components)-1 && length > maxLength; i++ {
+		short := abbreviate(components[i])
+		length -= utf8.RuneCountInString(components[i]) - utf8.RuneCountInString(short)
+		components[i] = short
+	}
+
+	return strings.Join(components, "/")
+}
+
+// abbreviate shortens one path component to its first character, keeping a leading dot
+func abbreviate(component string) string {
+	prefix := ""
+	if strings.HasPrefix(component, ".") && len(component) > 1 {
+		prefix, component = ".", component[1:]
+	}
+
+	first, size := utf8.DecodeRuneInString(component)
+	if first == utf8.RuneError || component == "~" {
+		return prefix + component
+	}
+	return prefix + component[:size]
+}
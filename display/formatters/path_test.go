@@ -0,0 +1,72 @@
+package formatters
+
+import (
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+func TestShortenPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		maxLength int
+		want      string
+	}{
+		{path: "internal/auth", maxLength: 30, want: "internal/auth"},
+		{path: "~/projects/api/internal/auth", maxLength: 20, want: "~/p/a/internal/auth"},
+		{path: "~/.config/nvim/lua/plugins", maxLength: 10, want: "~/.c/n/l/plugins"},
+		{path: "/usr/local/share/very/deep", maxLength: 20, want: "/u/l/share/very/deep"},
+		{path: "~/проекты/api", maxLength: 8, want: "~/п/api"},
+		{path: "single-very-long-component", maxLength: 5, want: "single-very-long-component"},
+	}
+
+	for _, tt := range tests {
+		if got := ShortenPath(tt.path, tt.maxLength); got != tt.want {
+			t.Errorf("ShortenPath(%q, %d) = %q, want %q", tt.path, tt.maxLength, got, tt.want)
+		}
+	}
+}
+
+func TestPathSegment(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace parser.Workspace
+		wantIcon  string
+		wantText  string
+	}{
+		{
+			name:      "project root",
+			workspace: parser.Workspace{CurrentDir: "/home/dev/api", ProjectDir: "/home/dev/api"},
+			wantIcon:  "⌂", wantText: "api",
+		},
+		{
+			name:      "inside project",
+			workspace: parser.Workspace{CurrentDir: "/home/dev/api/internal/auth", ProjectDir: "/home/dev/api"},
+			wantIcon:  "⌂", wantText: "api ▸ internal/auth",
+		},
+		{
+			name:      "outside project",
+			workspace: parser.Workspace{CurrentDir: "/home/dev/other-repo/src", ProjectDir: "/home/dev/api"},
+			wantIcon:  "⚠", wantText: "~/other-repo/src",
+		},
+		{
+			name:      "sibling with common prefix",
+			workspace: parser.Workspace{CurrentDir: "/home/dev/api-v2", ProjectDir: "/home/dev/api"},
+			wantIcon:  "⚠", wantText: "~/api-v2",
+		},
+		{
+			name:      "no project",
+			workspace: parser.Workspace{CurrentDir: "/home/dev"},
+			wantIcon:  "⌂", wantText: "~",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			icon, text, _ := PathSegment(tt.workspace, "/home/dev").Render(UnicodeGlyphs)
+			if icon != tt.wantIcon || text != tt.wantText {
+				t.Errorf("Render() = %q %q, want %q %q", icon, text, tt.wantIcon, tt.wantText)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package formatters
 
 import (
+	"math"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -21,25 +22,23 @@ const (
 // Parameters:
 //   - percentage: 0-100 value representing fill level
 //   - totalBlocks: number of character positions for the bar
-//   - fractionalBlocks: slice of partial block characters (HorizontalBlocks or VerticalBlocks)
+//   - glyphs: bar characters from the active glyph set (HorizontalBar or VerticalBar)
 //   - filledStyle: lipgloss style for filled portion
 //   - emptyStyle: lipgloss style for empty portion
 //
-// Returns a styled string with the progress bar.
+// Returns a styled string with the progress bar, exactly totalBlocks characters wide.
 func RenderProgressBar(
 	percentage float64,
 	totalBlocks int,
-	fractionalBlocks []string,
+	glyphs BarGlyphs,
 	filledStyle, emptyStyle lipgloss.Style,
 ) string {
-	// Clamp percentage to valid range
-	if percentage < 0 {
-		percentage = 0
-	}
-	if percentage > 100 {
-		percentage = 100
+	if totalBlocks <= 0 {
+		return ""
 	}
 
+	percentage = clampPercentage(percentage)
+
 	// Calculate total segments (8 per block for 1/8 precision)
 	totalSegments := totalBlocks * 8
 	filledSegments := int(percentage * float64(totalSegments) / 100.0)
@@ -52,14 +51,14 @@ func RenderProgressBar(
 	var filledPart strings.Builder
 
 	// Add full blocks
-	filledPart.WriteString(strings.Repeat(fullBlock, fullBlocks))
+	filledPart.WriteString(strings.Repeat(glyphs.Full, fullBlocks))
 
 	// Track how many character positions we've used
 	usedBlocks := fullBlocks
 
-	// Add fractional block if there's a remainder and space available
-	if remainder > 0 && usedBlocks < totalBlocks {
-		filledPart.WriteString(fractionalBlocks[remainder])
+	// Add fractional block if there's a remainder, space available and the glyph set supports it
+	if remainder > 0 && usedBlocks < totalBlocks && remainder < len(glyphs.Partial) {
+		filledPart.WriteString(glyphs.Partial[remainder])
 		usedBlocks++
 	}
 
@@ -69,8 +68,20 @@ func RenderProgressBar(
 	// Add empty blocks
 	emptyCount := totalBlocks - usedBlocks
 	if emptyCount > 0 {
-		result += emptyStyle.Render(strings.Repeat(emptyBlock, emptyCount))
+		result += emptyStyle.Render(strings.Repeat(glyphs.Empty, emptyCount))
 	}
 
 	return result
 }
+
+// clampPercentage limits a percentage to 0-100, mapping NaN to 0
+func clampPercentage(percentage float64) float64 {
+	switch {
+	case math.IsNaN(percentage) || percentage < 0:
+		return 0
+	case percentage > 100:
+		return 100
+	default:
+		return percentage
+	}
+}
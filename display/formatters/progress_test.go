@@ -0,0 +1,91 @@
+package formatters
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// barGlyphVariants covers every glyph set plus partial slices shorter than the 8 steps the bar expects
+var barGlyphVariants = map[string]BarGlyphs{
+	"unicode horizontal": UnicodeGlyphs.HorizontalBar,
+	"unicode vertical":   UnicodeGlyphs.VerticalBar,
+	"ascii":              ASCIIGlyphs.HorizontalBar,
+	"short partial":      {Full: "#", Empty: ".", Partial: []string{"", ":"}},
+	"empty partial":      {Full: "#", Empty: ".", Partial: []string{}},
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		want       string
+	}{
+		{percentage: 0, want: "░░░░░░░░░░"},
+		{percentage: 50, want: "█████░░░░░"},
+		{percentage: 100, want: "██████████"},
+		{percentage: 55, want: "█████▌░░░░"},
+		{percentage: 150, want: "██████████"},
+		{percentage: -20, want: "░░░░░░░░░░"},
+		{percentage: math.NaN(), want: "░░░░░░░░░░"},
+		{percentage: math.Inf(1), want: "██████████"},
+	}
+
+	for _, tt := range tests {
+		got := RenderProgressBar(tt.percentage, 10, UnicodeGlyphs.HorizontalBar, lipgloss.NewStyle(), lipgloss.NewStyle())
+		if got != tt.want {
+			t.Errorf("RenderProgressBar(%v) = %q, want %q", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+// TestRenderProgressBarWidthProperty checks that any percentage yields exactly totalBlocks characters
+func TestRenderProgressBarWidthProperty(t *testing.T) {
+	for name, glyphs := range barGlyphVariants {
+		t.Run(name, func(t *testing.T) {
+			property := func(percentage float64, blocks uint8) bool {
+				totalBlocks := int(blocks % 64)
+				bar := RenderProgressBar(percentage, totalBlocks, glyphs, lipgloss.NewStyle(), lipgloss.NewStyle())
+				return lipgloss.Width(bar) == totalBlocks
+			}
+
+			if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRenderProgressBarNonPositiveBlocks(t *testing.T) {
+	for _, totalBlocks := range []int{0, -1, math.MinInt32} {
+		if bar := RenderProgressBar(100, totalBlocks, UnicodeGlyphs.HorizontalBar, lipgloss.NewStyle(), lipgloss.NewStyle()); bar != "" {
+			t.Errorf("RenderProgressBar(100, %d) = %q, want empty", totalBlocks, bar)
+		}
+	}
+}
+
+func FuzzRenderProgressBar(f *testing.F) {
+	f.Add(0.0, 10, 8)
+	f.Add(55.5, 10, 8)
+	f.Add(math.NaN(), 10, 2)
+	f.Add(math.Inf(-1), 20, 0)
+	f.Add(1e308, 1, 8)
+	f.Add(-0.0001, 0, 3)
+
+	f.Fuzz(func(t *testing.T, percentage float64, totalBlocks int, partialSteps int) {
+		// Keep the bar small enough for the fuzzer to stay fast
+		totalBlocks %= 256
+		if partialSteps < 0 {
+			partialSteps = -partialSteps
+		}
+		glyphs := BarGlyphs{Full: "#", Empty: ".", Partial: HorizontalBlocks[:partialSteps%(len(HorizontalBlocks)+1)]}
+
+		bar := RenderProgressBar(percentage, totalBlocks, glyphs, lipgloss.NewStyle(), lipgloss.NewStyle())
+
+		want := max(totalBlocks, 0)
+		if width := lipgloss.Width(bar); width != want {
+			t.Errorf("width = %d, want %d for percentage %v", width, want, percentage)
+		}
+	})
+}
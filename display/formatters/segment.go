@@ -0,0 +1,30 @@
+package formatters
+
+import "github.com/charmbracelet/lipgloss"
+
+// Segment is an optional segment that every style renders after its built-in ones,
+// so new data sources don't need changes to each formatter
+type Segment struct {
+	Name string // Catalog name, used for error markers
+
+	// Render returns the segment for the active glyph set; empty text hides it
+	Render func(glyphs *GlyphSet) (icon, text string, style lipgloss.Style)
+}
+
+// appendExtra renders the optional segments from Options.Extra. Styles built around
+// icons pass alwaysIcons; the others show icons only when the glyph set asks for them.
+func (o Options) appendExtra(segments []string, alwaysIcons bool) []string {
+	for _, extra := range o.Extra {
+		segments = o.appendSegment(segments, extra.Name, func() string {
+			icon, text, style := extra.Render(o.glyphs())
+			if text == "" {
+				return ""
+			}
+			if alwaysIcons && icon != "" {
+				return style.Render(icon + " " + text)
+			}
+			return style.Render(o.label(icon, text))
+		})
+	}
+	return segments
+}
@@ -12,14 +12,38 @@ func init() {
 
 // Shared color definitions for all formatters
 var (
-	modelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("208")) // Claude orange for model
-	branchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // Red for git branch
-	greenStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("76"))  // Green for additions
-	redStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")) // Red for deletions
-	styleColor  = lipgloss.NewStyle().Foreground(lipgloss.Color("24"))  // Dark desaturated blue for output style
-	blueStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("111")) // Blue for version
-	grayStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("242")) // Gray for separator
-	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("238")) // Dim gray for empty blocks
-	whiteStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("255")) // White for context bar
-	lineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("232")) // Almost black for border lines
+	modelStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("208")) // Claude orange for model
+	branchStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // Red for git branch
+	greenStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("76"))  // Green for additions
+	redStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("203")) // Red for deletions
+	styleColor     = lipgloss.NewStyle().Foreground(lipgloss.Color("24"))  // Dark desaturated blue for output style
+	blueStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("111")) // Blue for version
+	grayStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("242")) // Gray for separator
+	dimStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("238")) // Dim gray for empty blocks
+	whiteStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("255")) // White for context bar
+	lineStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("232")) // Almost black for border lines
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("214")) // Amber for broken segment markers
+	locationStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("141")) // Purple for worktree and submodule location
+	pathStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("180")) // Tan for the working directory
+	toolchainStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("73"))  // Teal for language toolchains
+	commandStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("250")) // Light gray for custom commands without a color
+	infraStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("67"))  // Steel blue for infrastructure contexts
+	costStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("179")) // Gold for session cost
+	durationStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("146")) // Lavender for session duration
+	editsStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("151")) // Pale green for Claude's session edits
+	usageStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("144")) // Khaki for usage across sessions
+	blockStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("109")) // Grayish cyan for the usage block
+	apiTimeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("110")) // Light blue for API time
+	// Bold orange-red for a working directory outside the project
+	pathWarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true)
+	// Bold white on red for changes on a protected branch
+	protectedBranchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("231")).Background(lipgloss.Color("196")).Bold(true)
+	// Yellow for files modified in the working tree, bold red for conflicted files
+	modifiedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
+	conflictStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	// Bold red for an infrastructure context matching a danger pattern
+	infraDangerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	// Yellow, then bold red, for a usage block projected to approach or pass its limit
+	blockWarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
+	blockDangerStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
 )
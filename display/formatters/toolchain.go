@@ -0,0 +1,36 @@
+package formatters
+
+import (
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ToolchainSegment lists the project's toolchains, e.g. "go 1.22 python >=3.11 (.venv)".
+// With icons, the language icon replaces its name.
+func ToolchainSegment(toolchains []metrics.Toolchain) Segment {
+	return Segment{
+		Name: "toolchain",
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			parts := make([]string, 0, len(toolchains))
+			for _, toolchain := range toolchains {
+				name := toolchain.Language
+				if icon := glyphs.Icons.LanguageIcon(name); icon != "" && glyphs.IconsInAllStyles {
+					name = icon
+				}
+
+				part := name
+				if toolchain.Version != "" {
+					part += " " + toolchain.Version
+				}
+				if toolchain.Active != "" {
+					part += " (" + toolchain.Active + ")"
+				}
+				parts = append(parts, part)
+			}
+
+			return "", strings.Join(parts, " "), toolchainStyle
+		},
+	}
+}
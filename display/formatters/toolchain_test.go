@@ -0,0 +1,33 @@
+package formatters
+
+import (
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/metrics"
+)
+
+func TestToolchainSegment(t *testing.T) {
+	segment := ToolchainSegment([]metrics.Toolchain{
+		{Language: "go", Version: "1.22"},
+		{Language: "python", Version: ">=3.11", Active: ".venv"},
+		{Language: "zig"},
+	})
+
+	tests := []struct {
+		glyphs *GlyphSet
+		want   string
+	}{
+		{glyphs: UnicodeGlyphs, want: "go 1.22 python >=3.11 (.venv) zig"},
+		{glyphs: NerdFontGlyphs, want: "\ue627 1.22 \ue73c >=3.11 (.venv) zig"},
+	}
+
+	for _, tt := range tests {
+		if _, got, _ := segment.Render(tt.glyphs); got != tt.want {
+			t.Errorf("Render(%s) = %q, want %q", tt.glyphs.Level, got, tt.want)
+		}
+	}
+
+	if _, got, _ := ToolchainSegment(nil).Render(UnicodeGlyphs); got != "" {
+		t.Errorf("Render() without toolchains = %q, want empty", got)
+	}
+}
@@ -0,0 +1,22 @@
+package formatters
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// UsageSegment shows what every session together used over a period, e.g.
+// "today $12.40 1.2M tok 3h10m". label names the period.
+func UsageSegment(name, label string, costUSD float64, tokens int, duration time.Duration) Segment {
+	return Segment{
+		Name: name,
+		Render: func(glyphs *GlyphSet) (string, string, lipgloss.Style) {
+			if tokens == 0 {
+				return "", "", usageStyle
+			}
+			return "", fmt.Sprintf("%s $%.2f %s tok %s", label, costUSD, HumanizeTokens(tokens), HumanizeDuration(duration)), usageStyle
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package formatters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageSegment(t *testing.T) {
+	segment := UsageSegment("usage-day", "today", 12.4, 1_234_567, 3*time.Hour+10*time.Minute)
+	if _, got, _ := segment.Render(UnicodeGlyphs); got != "today $12.40 1.2M tok 3h10m" {
+		t.Errorf("Render() = %q", got)
+	}
+
+	if _, got, _ := UsageSegment("usage-week", "week", 0, 0, 0).Render(UnicodeGlyphs); got != "" {
+		t.Errorf("Render() without usage = %q, want empty", got)
+	}
+}
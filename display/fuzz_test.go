@@ -0,0 +1,69 @@
+package display
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// renderEveryStyle formats the inputs with every style and glyph set, failing on empty output
+func renderEveryStyle(t *testing.T, hook *parser.StatusHook, tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo) {
+	t.Helper()
+
+	for _, level := range formatters.GlyphLevels {
+		for _, style := range Styles {
+			formatter := NewFormatter(style.Name, formatters.Options{Glyphs: formatters.GlyphSetFor(level)})
+			if formatter.Format(hook, tokenMetrics, gitInfo) == "" {
+				t.Errorf("%s/%s rendered an empty status line", style.Name, level)
+			}
+		}
+	}
+}
+
+func FuzzRenderHook(f *testing.F) {
+	paths, _ := filepath.Glob(filepath.Join("testdata", "hooks", "*.json"))
+	for _, path := range paths {
+		if data, err := os.ReadFile(path); err == nil {
+			f.Add(data)
+		}
+	}
+	f.Add([]byte(`{"context_window":{"context_window_size":-1,"current_usage":{"input_tokens":-5}}}`))
+	f.Add([]byte(`{"context_window":{"context_window_size":1,"total_input_tokens":9223372036854775807}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		hook, _, err := parser.Decode(data, parser.ParseLenient)
+		if err != nil {
+			return
+		}
+
+		gitInfo := &metrics.GitInfo{IsGitRepo: true, Branch: "main", BranchDisplay: "main", Additions: len(data), Deletions: -len(data)}
+		renderEveryStyle(t, hook, metrics.CalculateTokenMetrics(hook.ContextWindow), gitInfo)
+	})
+}
+
+func FuzzRenderMetrics(f *testing.F) {
+	f.Add(0.0, 0, 0)
+	f.Add(100.0, 200000, 200000)
+	f.Add(250.0, 500000, 200000)
+	f.Add(math.NaN(), 1, 0)
+	f.Add(math.Inf(1), -1, -1)
+	f.Add(-42.0, -100, 200000)
+	f.Add(1e308, math.MaxInt, math.MinInt)
+
+	hook := &parser.StatusHook{Model: parser.Model{DisplayName: "Opus"}, Version: "2.0.0"}
+	gitInfo := &metrics.GitInfo{IsGitRepo: true, Branch: "main", BranchDisplay: "main", HasChanges: true, ChangesText: "(+1 -1)", Additions: 1, Deletions: 1}
+
+	f.Fuzz(func(t *testing.T, percentage float64, length, size int) {
+		tokenMetrics := &metrics.TokenMetrics{
+			ContextLength:     length,
+			ContextPercentage: percentage,
+			ContextWindowSize: size,
+		}
+		renderEveryStyle(t, hook, tokenMetrics, gitInfo)
+	})
+}
@@ -0,0 +1,144 @@
+package display
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// goldenProfiles are the color profiles every fixture is rendered with.
+// Ascii keeps golden files readable; TrueColor catches style regressions.
+var goldenProfiles = []struct {
+	Name    string
+	Profile termenv.Profile
+}{
+	{Name: "plain", Profile: termenv.Ascii},
+	{Name: "truecolor", Profile: termenv.TrueColor},
+}
+
+// goldenSegments are the optional segments rendered with every fixture; fixtureSegments
+// adds segments for the fixtures that exist to exercise them. goldenHome is the home
+// directory, so the output doesn't depend on who runs the tests.
+var (
+	goldenSegments  []string
+	fixtureSegments = map[string][]string{
+		"subdirectory":    {"path"},
+		"outside-project": {"path"},
+		"path-basic":      {"path"},
+	}
+	goldenHome = "/home/dev"
+)
+
+// fixture is a hook payload with the git state it should be rendered against
+type fixture struct {
+	Name string
+	Hook *parser.StatusHook
+	Git  *metrics.GitInfo
+}
+
+// TestGoldenRendering renders every fixture with every style and glyph set and
+// compares the result with testdata/golden. Run with -update to accept changes.
+func TestGoldenRendering(t *testing.T) {
+	fixtures := loadFixtures(t)
+
+	for _, profile := range goldenProfiles {
+		for _, fx := range fixtures {
+			t.Run(fx.Name+"/"+profile.Name, func(t *testing.T) {
+				lipgloss.SetColorProfile(profile.Profile)
+				t.Cleanup(func() { lipgloss.SetColorProfile(termenv.TrueColor) })
+
+				got := renderAll(fx)
+				path := filepath.Join("testdata", "golden", fmt.Sprintf("%s.%s.golden", fx.Name, profile.Name))
+
+				if *update {
+					if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+						t.Fatalf("failed to update golden file: %v", err)
+					}
+					return
+				}
+
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+				}
+				if got != string(want) {
+					t.Errorf("output differs from %s (run with -update to accept)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+				}
+			})
+		}
+	}
+}
+
+// renderAll renders a fixture with every style and glyph set into one document
+func renderAll(fx fixture) string {
+	var out strings.Builder
+	tokenMetrics := metrics.CalculateTokenMetrics(fx.Hook.ContextWindow)
+	segments := append(slices.Clip(goldenSegments), fixtureSegments[fx.Name]...)
+	extra := Extras(segments, ExtraData{Hook: fx.Hook, Home: goldenHome})
+
+	for _, level := range formatters.GlyphLevels {
+		for _, style := range Styles {
+			formatter := NewFormatter(style.Name, formatters.Options{Glyphs: formatters.GlyphSetFor(level), Extra: extra})
+			fmt.Fprintf(&out, "=== %s / %s ===\n%s\n\n", style.Name, level, formatter.Format(fx.Hook, tokenMetrics, fx.Git))
+		}
+	}
+
+	return out.String()
+}
+
+// loadFixtures reads testdata/hooks/<name>.json with its optional <name>.git.json git state
+func loadFixtures(t *testing.T) []fixture {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "hooks", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+
+	var fixtures []fixture
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".git.json") {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		hook, _, err := parser.Decode(data, parser.ParseStrict)
+		if err != nil {
+			t.Fatalf("invalid fixture %s: %v", path, err)
+		}
+
+		// Fixtures without git state render as outside a repository
+		gitInfo := &metrics.GitInfo{BranchDisplay: "(no git)", ChangesText: "(no git)"}
+		gitPath := strings.TrimSuffix(path, ".json") + ".git.json"
+		if data, err := os.ReadFile(gitPath); err == nil {
+			if err := json.Unmarshal(data, gitInfo); err != nil {
+				t.Fatalf("invalid git fixture %s: %v", gitPath, err)
+			}
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		fixtures = append(fixtures, fixture{Name: name, Hook: hook, Git: gitInfo})
+	}
+
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found in testdata/hooks")
+	}
+
+	return fixtures
+}
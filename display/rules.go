@@ -0,0 +1,175 @@
+package display
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/DieGopherLT/cc-status-line/rules"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// targetAliases maps rule targets to segment names where the value prefix differs
+var targetAliases = map[string]string{
+	"ctx": "context",
+}
+
+// RuleValues builds the snapshot styling rules are evaluated against, e.g. ctx.pct,
+// git.branch or cost.total. Paths under "hook." reach any field of the raw hook input.
+// Values the payload's Claude Code version doesn't send are left out rather than zero, so
+// a rule like "ctx.pct < 10" doesn't match on older versions.
+func RuleValues(tokenMetrics *metrics.TokenMetrics, gitInfo *metrics.GitInfo, data ExtraData) rules.Lookup {
+	values := make(map[string]any)
+	hook := data.Hook
+
+	if hook != nil {
+		values["model.id"] = hook.Model.ID
+		values["model.name"] = hook.Model.DisplayName
+		values["version"] = hook.Version
+		values["path.cwd"] = hook.Workspace.CurrentDir
+		values["path.project"] = hook.Workspace.ProjectDir
+		if hook.Supports(parser.CapOutputStyle) {
+			values["style"] = hook.OutputStyle.Name
+		}
+		if hook.Supports(parser.CapCost) {
+			values["cost.total"] = hook.Cost.TotalCostUSD
+			values["cost.duration"] = float64(hook.Cost.TotalDurationMS) / 1000
+			values["cost.api_duration"] = float64(hook.Cost.TotalAPIDurationMS) / 1000
+			values["cost.api_pct"] = formatters.APIRatio(hook.Cost)
+		}
+		if hook.Supports(parser.CapLineCounts) {
+			values["cost.lines_added"] = hook.Cost.TotalLinesAdded
+			values["cost.lines_removed"] = hook.Cost.TotalLinesRemoved
+		}
+	}
+
+	if tokenMetrics != nil && (hook == nil || hook.Supports(parser.CapContextWindow)) {
+		values["ctx.pct"] = tokenMetrics.ContextPercentage
+		values["ctx.tokens"] = tokenMetrics.ContextLength
+		values["ctx.size"] = tokenMetrics.ContextWindowSize
+	}
+
+	if gitInfo != nil {
+		values["git.repo"] = gitInfo.IsGitRepo
+		if gitInfo.IsGitRepo {
+			values["git.branch"] = gitInfo.Branch
+			values["git.detached"] = gitInfo.Detached
+			values["git.tag"] = gitInfo.Tag
+			values["git.commit"] = gitInfo.Commit
+			values["git.dirty"] = gitInfo.HasChanges
+			values["git.added"] = gitInfo.Additions
+			values["git.deleted"] = gitInfo.Deletions
+			values["git.staged"] = gitInfo.Staged
+			values["git.modified"] = gitInfo.Modified
+			values["git.untracked"] = gitInfo.Untracked
+			values["git.conflicts"] = gitInfo.Conflicts
+			values["git.worktree"] = gitInfo.Worktree
+			values["git.superproject"] = gitInfo.Superproject
+			values["git.submodule"] = gitInfo.Submodule
+			values["git.dirty_submodules"] = gitInfo.DirtySubmodules
+			values["git.protected"] = data.Protected
+		}
+	}
+
+	for _, toolchain := range data.Toolchains {
+		values["toolchain."+toolchain.Language] = toolchain.Version
+	}
+	for name, context := range data.Infra {
+		if context != nil {
+			values[name+".name"] = context.Name
+			values[name+".detail"] = context.Detail
+		}
+	}
+	for name, totals := range data.Usage {
+		if totals != nil {
+			period := "usage." + strings.TrimPrefix(name, "usage-")
+			values[period+".cost"] = totals.CostUSD
+			values[period+".tokens"] = totals.Tokens.Total()
+			values[period+".duration"] = totals.Duration.Seconds()
+		}
+	}
+	if block := data.Block; block != nil {
+		values["block.tokens"] = block.Tokens
+		values["block.projected"] = block.Projected
+		values["block.pct"] = block.Percent()
+		values["block.projected_pct"] = block.ProjectedPercent()
+		values["block.remaining"] = block.Remaining.Seconds()
+	}
+	for name, output := range data.Commands {
+		values["command."+name] = output.Text
+	}
+
+	return func(path string) (any, bool) {
+		if rest, ok := strings.CutPrefix(path, "hook."); ok && hook != nil {
+			return hook.Lookup(rest)
+		}
+		value, ok := values[path]
+		return value, ok
+	}
+}
+
+// RuleSegments returns names followed by the optional segments and custom commands that
+// rules can show, so their data is collected even when the config doesn't enable them
+func RuleSegments(names, commands []string, parsed []*rules.Rule) []string {
+	segments := slices.Clone(names)
+	for _, target := range rules.ShownTargets(parsed) {
+		for _, name := range targetSegments(target) {
+			optional := IsOptionalSegment(name) || slices.Contains(commands, name)
+			if optional && !slices.Contains(segments, name) {
+				segments = append(segments, name)
+			}
+		}
+	}
+	return segments
+}
+
+// Overrides turns rule effects into per-segment overrides. Invalid styles are skipped;
+// doctor reports them.
+func Overrides(effects map[string]rules.Effect) map[string]formatters.Override {
+	overrides := make(map[string]formatters.Override)
+	// Sorted so a specific target like git-branch overrides a prefix like git
+	for _, target := range slices.Sorted(maps.Keys(effects)) {
+		effect := effects[target]
+		var style *lipgloss.Style
+		if parsed, err := formatters.ParseStyle(effect.Style); effect.Style != "" && err == nil {
+			style = &parsed
+		}
+
+		for _, name := range targetSegments(target) {
+			override := overrides[name]
+			override.Hidden = override.Hidden || !effect.Visible()
+			if style != nil {
+				override.Style = style
+			}
+			if effect.Icon != "" {
+				override.Icon = effect.Icon
+			}
+			overrides[name] = override
+		}
+	}
+	return overrides
+}
+
+// targetSegments resolves a rule target to segment names: a full name, an alias, or the
+// prefix shared by several segments ("git" for git-branch, git-changes and git-location).
+// Unknown targets are kept as they are, since they may name a custom command.
+func targetSegments(target string) []string {
+	if name, ok := targetAliases[target]; ok {
+		target = name
+	}
+
+	var names []string
+	for _, segment := range Segments {
+		short, _, _ := strings.Cut(segment.Name, "-")
+		if segment.Name == target || short == target {
+			names = append(names, segment.Name)
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, target)
+	}
+	return names
+}
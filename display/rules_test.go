@@ -0,0 +1,128 @@
+package display
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/DieGopherLT/cc-status-line/rules"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRuleValuesSkipUnsentFields(t *testing.T) {
+	// An older payload without context_window, cost or output_style
+	hook, _, err := parser.Decode([]byte(`{"version":"1.0.20","model":{"display_name":"Opus"},"workspace":{"current_dir":"/a","project_dir":"/a"}}`), parser.ParseStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := RuleValues(metrics.CalculateTokenMetrics(hook.ContextWindow), nil, ExtraData{Hook: hook})
+	for _, name := range []string{"ctx.pct", "cost.total", "cost.lines_added", "style"} {
+		if value, ok := values(name); ok {
+			t.Errorf("values(%q) = %v, want it absent", name, value)
+		}
+	}
+	if value, _ := values("model.name"); value != "Opus" {
+		t.Errorf("values(model.name) = %v, want Opus", value)
+	}
+
+	hook, _, err = parser.Decode([]byte(`{"version":"2.1.3","model":{"display_name":"Opus"},"workspace":{"current_dir":"/a","project_dir":"/a"},"cost":{"total_cost_usd":1.5},"context_window":{"context_window_size":200000,"current_usage":{"input_tokens":50000}}}`), parser.ParseStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values = RuleValues(metrics.CalculateTokenMetrics(hook.ContextWindow), nil, ExtraData{Hook: hook})
+	pct, _ := values("ctx.pct")
+	total, _ := values("cost.total")
+	if pct != 25.0 || total != 1.5 {
+		t.Errorf("values = ctx.pct %v, cost.total %v, want 25 and 1.5", pct, total)
+	}
+	if _, ok := values("cost.lines_added"); ok {
+		t.Error("values(cost.lines_added) set for a payload without line counts")
+	}
+}
+
+func TestRuleSegmentsShowsCommands(t *testing.T) {
+	parsed, errs := rules.ParseAll([]string{
+		`when ctx.pct > 80 then show app`,
+		`when cost.total > 5 then show cost`,
+		`when ctx.pct > 90 then show unknown`,
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	got := RuleSegments([]string{"path"}, []string{"app"}, parsed)
+	if want := []string{"path", "app", "cost"}; !slices.Equal(got, want) {
+		t.Errorf("RuleSegments() = %v, want %v", got, want)
+	}
+
+	// Without the command defined, only the built-in optional segment is added
+	got = RuleSegments(nil, nil, parsed)
+	if want := []string{"cost"}; !slices.Equal(got, want) {
+		t.Errorf("RuleSegments() without commands = %v, want %v", got, want)
+	}
+}
+
+// TestExampleRules runs the rules from the README through evaluation and rendering
+func TestExampleRules(t *testing.T) {
+	parsed, errs := rules.ParseAll([]string{
+		`when ctx.pct > 80 then ctx.style = "bold red blink"`,
+		`when git.branch matches "^(main|master)$" then git.style = "reverse"`,
+		`when cost.total > 5 then show cost`,
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	render := func(t *testing.T, costUSD float64, inputTokens int, branch string) (string, map[string]formatters.Override) {
+		t.Helper()
+
+		hook, _, err := parser.Decode(fmt.Appendf(nil, `{"version":"2.1.3","model":{"display_name":"Opus"},`+
+			`"workspace":{"current_dir":"/a","project_dir":"/a"},"cost":{"total_cost_usd":%g},`+
+			`"context_window":{"context_window_size":200000,"current_usage":{"input_tokens":%d}}}`, costUSD, inputTokens), parser.ParseStrict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokenMetrics := metrics.CalculateTokenMetrics(hook.ContextWindow)
+		gitInfo := &metrics.GitInfo{IsGitRepo: true, Branch: branch, BranchDisplay: branch, ChangesText: "(no changes)"}
+
+		data := ExtraData{Hook: hook}
+		overrides := Overrides(rules.Evaluate(parsed, RuleValues(tokenMetrics, gitInfo, data)))
+		formatter := NewFormatter("minimal", formatters.Options{
+			Glyphs:    formatters.ASCIIGlyphs,
+			Extra:     Extras(RuleSegments(nil, nil, parsed), data),
+			Overrides: overrides,
+		})
+		return ansi.Strip(formatter.Format(hook, tokenMetrics, gitInfo)), overrides
+	}
+
+	t.Run("matching", func(t *testing.T) {
+		output, overrides := render(t, 6.5, 170000, "main")
+
+		if style := overrides["context"].Style; style == nil || !style.GetBold() || !style.GetBlink() {
+			t.Errorf("context style = %v, want bold blink", style)
+		}
+		if style := overrides["git-branch"].Style; style == nil || !style.GetReverse() {
+			t.Errorf("git-branch style = %v, want reverse", style)
+		}
+		if !strings.Contains(output, "$6.50") {
+			t.Errorf("output = %q, want the cost shown", output)
+		}
+	})
+
+	t.Run("not matching", func(t *testing.T) {
+		output, overrides := render(t, 1.25, 20000, "feature")
+
+		if overrides["context"].Style != nil || overrides["git-branch"].Style != nil {
+			t.Errorf("overrides = %+v, want no restyling", overrides)
+		}
+		if strings.Contains(output, "$1.25") {
+			t.Errorf("output = %q, want the cost hidden", output)
+		}
+	})
+}
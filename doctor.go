@@ -0,0 +1,412 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/metrics"
+	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/DieGopherLT/cc-status-line/rules"
+	"github.com/DieGopherLT/cc-status-line/settings"
+	"github.com/muesli/termenv"
+)
+
+// checkStatus is the outcome of a single doctor check
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+func (s checkStatus) String() string {
+	switch s {
+	case checkOK:
+		return "[ ok ]"
+	case checkWarn:
+		return "[warn]"
+	default:
+		return "[fail]"
+	}
+}
+
+// checkResult is one line of the doctor report, with optional detail lines
+type checkResult struct {
+	Name    string
+	Status  checkStatus
+	Message string
+	Details []string
+}
+
+// runDoctor checks the environment the status line runs in and reports problems
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	checks := []checkResult{
+		checkGit(),
+		checkGlyphs(),
+		checkColors(),
+		checkConfig(),
+		checkCommands(),
+		checkSettings(),
+	}
+
+	exitCode := 0
+	for _, check := range checks {
+		fmt.Printf("%s %-8s %s\n", check.Status, check.Name, check.Message)
+		for _, detail := range check.Details {
+			fmt.Printf("                %s\n", detail)
+		}
+		if check.Status == checkFail {
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+// checkGit verifies that git is installed, since git segments silently disappear without it
+func checkGit() checkResult {
+	result := checkResult{Name: "git"}
+
+	path, err := exec.LookPath("git")
+	if err != nil {
+		result.Status = checkWarn
+		result.Message = "git not found in PATH; git segments will be hidden"
+		return result
+	}
+
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		result.Status = checkWarn
+		result.Message = fmt.Sprintf("%s --version failed: %v", path, err)
+		return result
+	}
+
+	result.Message = fmt.Sprintf("%s (%s)", strings.TrimSpace(string(output)), path)
+	return result
+}
+
+// checkGlyphs reports the detected glyph level and prints samples so users can check their font
+func checkGlyphs() checkResult {
+	level := formatters.DetectGlyphLevel()
+	result := checkResult{
+		Name:    "glyphs",
+		Message: fmt.Sprintf("auto-detected %q (TERM=%q, locale=%q)", level, os.Getenv("TERM"), currentLocale()),
+	}
+
+	if level == formatters.GlyphsASCII {
+		result.Status = checkWarn
+		result.Message += "; use a UTF-8 locale for block and box characters"
+	}
+
+	result.Details = append(result.Details, "If a row below shows boxes or question marks, your font lacks that set:")
+	for _, candidate := range formatters.GlyphLevels {
+		result.Details = append(result.Details, fmt.Sprintf("%-9s %s", candidate, glyphSample(formatters.GlyphSetFor(candidate))))
+	}
+
+	return result
+}
+
+// checkColors compares the terminal's color support with the TrueColor output the formatters emit
+func checkColors() checkResult {
+	result := checkResult{Name: "colors"}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		result.Status = checkWarn
+		result.Message = "NO_COLOR is set, but the status line always emits colors"
+		return result
+	}
+
+	switch profile := termenv.EnvColorProfile(); profile {
+	case termenv.TrueColor:
+		result.Message = "terminal supports TrueColor"
+	case termenv.ANSI256:
+		result.Message = "terminal supports 256 colors"
+	default:
+		result.Status = checkWarn
+		result.Message = fmt.Sprintf("terminal reports limited color support (COLORTERM=%q, TERM=%q); colors may be approximated",
+			os.Getenv("COLORTERM"), os.Getenv("TERM"))
+	}
+
+	return result
+}
+
+// checkConfig validates the config file if one exists
+func checkConfig() checkResult {
+	result := checkResult{Name: "config"}
+
+	path, err := config.Path()
+	if err != nil {
+		result.Status = checkFail
+		result.Message = err.Error()
+		return result
+	}
+
+	cfg := config.Default()
+	found := true
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		found = false
+	} else if cfg, err = config.Load(path); err != nil {
+		result.Status = checkFail
+		result.Message = err.Error()
+		return result
+	}
+
+	// Project files of the current directory layer over the user config, as when rendering.
+	// Each file is applied in turn, so the problems it adds are reported against it.
+	dir, _ := os.Getwd()
+	projectFiles := config.ProjectFiles(dir)
+	if !found && len(projectFiles) == 0 {
+		result.Message = fmt.Sprintf("no config file at %s; using defaults", path)
+		return result
+	}
+
+	var failures []string
+	problems := configProblems(cfg)
+	if len(problems) > 0 {
+		failures = append(failures, fmt.Sprintf("%s: %s", path, strings.Join(problems, ", ")))
+	}
+	for _, file := range projectFiles {
+		result.Details = append(result.Details, "project config: "+file)
+
+		layered, _, err := cfg.WithFiles([]string{file})
+		if err != nil {
+			// Load errors name the file already
+			failures = append(failures, strings.Split(err.Error(), "\n")...)
+		}
+		var added []string
+		for _, problem := range configProblems(layered) {
+			if !slices.Contains(problems, problem) {
+				added = append(added, problem)
+			}
+		}
+		if len(added) > 0 {
+			failures = append(failures, fmt.Sprintf("%s: %s", file, strings.Join(added, ", ")))
+		}
+		cfg, problems = layered, configProblems(layered)
+	}
+
+	if len(failures) > 0 {
+		result.Status = checkFail
+		result.Message = strings.Join(failures, "; ")
+		return result
+	}
+
+	if !found {
+		result.Message = fmt.Sprintf("no config file at %s; project config is valid", path)
+		return result
+	}
+	result.Message = fmt.Sprintf("%s is valid", path)
+	return result
+}
+
+// configProblems lists the settings of cfg the status line can't use
+func configProblems(cfg *config.Config) []string {
+	var problems []string
+	if !display.IsStyle(cfg.Style) {
+		problems = append(problems, fmt.Sprintf("unknown style %q", cfg.Style))
+	}
+	if !isGlyphSetName(cfg.Glyphs) {
+		problems = append(problems, fmt.Sprintf("unknown glyph set %q", cfg.Glyphs))
+	}
+	if mode := parser.ParseMode(cfg.ParseMode); mode != parser.ParseLenient && mode != parser.ParseStrict {
+		problems = append(problems, fmt.Sprintf("unknown parse mode %q", cfg.ParseMode))
+	}
+	if cfg.BlockTokenLimit < 0 {
+		problems = append(problems, fmt.Sprintf("negative block_token_limit %d", cfg.BlockTokenLimit))
+	}
+	if cfg.Changes != "diff" && cfg.Changes != "session" {
+		problems = append(problems, fmt.Sprintf("unknown changes mode %q (want diff or session)", cfg.Changes))
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Danger)) {
+		patterns := cfg.Danger[name]
+		if _, ok := metrics.InfraDetectors[name]; !ok {
+			problems = append(problems, fmt.Sprintf("danger patterns for unknown segment %q", name))
+		}
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid danger pattern %q for %s", pattern, name))
+			}
+		}
+	}
+	problems = append(problems, branchPatternProblems(cfg.ProtectedBranches)...)
+	parsedRules, ruleErrors := rules.ParseAll(cfg.Rules)
+	for _, err := range ruleErrors {
+		problems = append(problems, err.Error())
+	}
+	for _, rule := range parsedRules {
+		for _, action := range rule.Actions {
+			if action.Kind != rules.ActionStyle {
+				continue
+			}
+			if _, err := formatters.ParseStyle(action.Value); err != nil {
+				problems = append(problems, fmt.Sprintf("rule %q: %v", rule.Source, err))
+			}
+		}
+	}
+	_, errs := validCommands(cfg.Commands)
+	for _, err := range errs {
+		problems = append(problems, err.Error())
+	}
+	commands := make(map[string]bool)
+	for _, command := range cfg.Commands {
+		commands[command.Name] = true
+	}
+	for _, segment := range cfg.Segments {
+		if !display.IsOptionalSegment(segment) && !commands[segment] {
+			problems = append(problems, fmt.Sprintf("unknown optional segment %q", segment))
+		}
+	}
+
+	return problems
+}
+
+// checkCommands runs the custom command segments enabled in the config from the current
+// directory, bypassing the cache, and shows what each would display
+func checkCommands() checkResult {
+	result := checkResult{Name: "commands"}
+
+	dir, _ := os.Getwd()
+	cfg, err := config.LoadDefault()
+	if err == nil {
+		cfg, _, err = cfg.WithProject(dir)
+	}
+	if err != nil {
+		result.Status = checkWarn
+		result.Message = "config could not be loaded; see above"
+		return result
+	}
+
+	hook := &parser.StatusHook{Workspace: parser.Workspace{CurrentDir: dir, ProjectDir: dir}}
+
+	ran := 0
+	commands, _ := validCommands(cfg.Commands)
+	for _, command := range commands {
+		if !slices.Contains(cfg.Segments, command.Name) {
+			continue
+		}
+		ran++
+
+		output, err := metrics.RunCommand(command.Run, commandDir(command, hook), nil, command.Timeout)
+		if err != nil {
+			result.Status = checkWarn
+			result.Details = append(result.Details, fmt.Sprintf("%s: %v", command.Name, err))
+			continue
+		}
+		switch text, ok, err := metrics.ExtractOutput(output, command.Match); {
+		case err != nil:
+			result.Status = checkWarn
+			result.Details = append(result.Details, fmt.Sprintf("%s: %v", command.Name, err))
+		case !ok:
+			result.Details = append(result.Details, fmt.Sprintf("%s: no match in %q; hidden", command.Name, output))
+		default:
+			result.Details = append(result.Details, fmt.Sprintf("%s: %q", command.Name, text))
+		}
+	}
+
+	switch {
+	case ran == 0:
+		result.Message = "no custom command segments enabled"
+	case result.Status == checkWarn:
+		result.Message = "a command failed; its segment shows an error marker"
+	default:
+		result.Message = fmt.Sprintf("%d command segments ran", ran)
+	}
+	return result
+}
+
+// checkSettings reports which Claude Code settings files configure a status line
+func checkSettings() checkResult {
+	result := checkResult{Name: "settings"}
+
+	var configured []string
+	for _, scope := range []settings.Scope{settings.ScopeUser, settings.ScopeProject, settings.ScopeLocal} {
+		path, err := settings.Path(scope, ".")
+		if err != nil {
+			continue
+		}
+
+		statusLine, err := settings.Read(path)
+		if err != nil {
+			result.Status = checkFail
+			result.Details = append(result.Details, err.Error())
+			continue
+		}
+		if statusLine != nil {
+			configured = append(configured, string(scope))
+			result.Details = append(result.Details, fmt.Sprintf("%s: %s", path, statusLine.Command))
+		}
+	}
+
+	switch {
+	case result.Status == checkFail:
+		result.Message = "a settings file could not be read"
+	case len(configured) == 0:
+		result.Status = checkWarn
+		result.Message = "no statusLine configured; run \"cc-status-line install\""
+	default:
+		result.Message = fmt.Sprintf("statusLine configured in %s scope", strings.Join(configured, ", "))
+	}
+
+	return result
+}
+
+// branchPatternProblems reports protected branch patterns that are not valid globs
+func branchPatternProblems(patterns []string) []string {
+	var problems []string
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid protected branch pattern %q", pattern))
+		}
+	}
+	return problems
+}
+
+// isSegmentName reports whether name belongs to a built-in or optional segment
+func isSegmentName(name string) bool {
+	for _, segment := range display.Segments {
+		if segment.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isGlyphSetName reports whether name is accepted by --glyphs
+func isGlyphSetName(name string) bool {
+	if name == "auto" {
+		return true
+	}
+	for _, level := range formatters.GlyphLevels {
+		if string(level) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// currentLocale returns the effective locale following POSIX precedence
+func currentLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
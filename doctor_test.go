@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+)
+
+func TestCheckConfigAttributesProblems(t *testing.T) {
+	root := t.TempDir()
+	userPath := filepath.Join(root, "user", "config.toml")
+	project := filepath.Join(root, "project")
+	projectPath := filepath.Join(project, config.ProjectFile)
+	for path, content := range map[string]string{
+		userPath:    `glyphs = "fancy"`,
+		projectPath: `style = "bogus"`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(config.EnvPath, userPath)
+	t.Chdir(project)
+
+	result := checkConfig()
+	if result.Status != checkFail {
+		t.Fatalf("Status = %v, want a failure", result.Status)
+	}
+	for _, want := range []string{
+		userPath + `: unknown glyph set "fancy"`,
+		projectPath + `: unknown style "bogus"`,
+	} {
+		if !strings.Contains(result.Message, want) {
+			t.Errorf("Message = %q, want %q", result.Message, want)
+		}
+	}
+	if strings.Contains(result.Message, userPath+`: unknown glyph set "fancy", unknown style`) {
+		t.Errorf("Message = %q attributes the project problem to the user config", result.Message)
+	}
+}
@@ -0,0 +1,136 @@
+package fswatch
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher shares one portable file watcher (inotify, kqueue, ReadDirectoryChangesW) between
+// callers. A handler registered for a file runs when the file changes; one registered for a
+// directory runs when any direct entry of the directory changes.
+type Watcher struct {
+	mu       sync.Mutex
+	notify   *fsnotify.Watcher
+	handlers map[string]func(changed string)
+}
+
+// New starts a watcher. Close releases its file descriptors and goroutine.
+func New() (*Watcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start file watcher: %w", err)
+	}
+
+	w := &Watcher{notify: notify, handlers: make(map[string]func(string))}
+	go w.run()
+	return w, nil
+}
+
+// Watch registers handler for path, replacing any previous handler for the same path.
+// Watches are not recursive: each directory of a tree needs its own call.
+func (w *Watcher) Watch(path string, handler func(changed string)) error {
+	path = filepath.Clean(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.handlers[path]; !ok {
+		if err := w.notify.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+	}
+	w.handlers[path] = handler
+	return nil
+}
+
+// Unwatch removes the handler for path and stops watching it
+func (w *Watcher) Unwatch(path string) {
+	path = filepath.Clean(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.handlers[path]; ok {
+		delete(w.handlers, path)
+		_ = w.notify.Remove(path)
+	}
+}
+
+// Watching reports whether a handler is registered for path
+func (w *Watcher) Watching(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, ok := w.handlers[filepath.Clean(path)]
+	return ok
+}
+
+// Close stops the watcher; handlers are not called afterwards
+func (w *Watcher) Close() error {
+	return w.notify.Close()
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			// Permission and timestamp changes don't alter contents
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			w.dispatch(event)
+		case err, ok := <-w.notify.Errors:
+			if !ok {
+				return
+			}
+			// Dropped events may hide any change, so everyone has to assume the worst
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				w.dispatchAll()
+			}
+		}
+	}
+}
+
+// dispatch calls the handlers of the changed path and of its directory
+func (w *Watcher) dispatch(event fsnotify.Event) {
+	changed := filepath.Clean(event.Name)
+
+	w.mu.Lock()
+	var handlers []func(string)
+	if handler, ok := w.handlers[changed]; ok {
+		handlers = append(handlers, handler)
+		// A removed or renamed path loses its watch; forget it so Watch can add it again
+		if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+			delete(w.handlers, changed)
+		}
+	}
+	if handler, ok := w.handlers[filepath.Dir(changed)]; ok {
+		handlers = append(handlers, handler)
+	}
+	w.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(changed)
+	}
+}
+
+// dispatchAll calls every handler with its own path
+func (w *Watcher) dispatchAll() {
+	w.mu.Lock()
+	handlers := make(map[string]func(string), len(w.handlers))
+	for path, handler := range w.handlers {
+		handlers[path] = handler
+	}
+	w.mu.Unlock()
+
+	for path, handler := range handlers {
+		handler(path)
+	}
+}
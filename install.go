@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/settings"
+)
+
+// binaryName is the command name used when the binary is reachable through PATH
+const binaryName = "cc-status-line"
+
+// runInstall adds the statusLine block to a Claude Code settings file
+func runInstall(args []string) int {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	scope := fs.String("scope", string(settings.ScopeUser), "Settings file to edit: user, project or local")
+	projectDir := fs.String("project-dir", ".", "Project directory for the project and local scopes")
+	style := fs.String("style", "", "Style passed with --style (default: config file or classic)")
+	glyphs := fs.String("glyphs", "", "Glyph set passed with --glyphs (default: config file or auto)")
+	padding := fs.Int("padding", 0, "statusLine padding")
+	command := fs.String("command", "", "Command to run (default: cc-status-line if in PATH, else this binary's absolute path)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *style != "" && !display.IsStyle(*style) {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown style %q\n", *style)
+		return 2
+	}
+	if *glyphs != "" && !isGlyphSetName(*glyphs) {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown glyph set %q\n", *glyphs)
+		return 2
+	}
+
+	path, err := settings.Path(settings.Scope(*scope), *projectDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 2
+	}
+
+	statusLine := settings.StatusLine{
+		Type:    "command",
+		Command: statusLineCommand(*command, *style, *glyphs),
+		Padding: *padding,
+	}
+
+	backup, changed, err := settings.Install(path, statusLine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 1
+	}
+
+	if !changed {
+		fmt.Printf("Status line already installed in %s\n", path)
+		fmt.Printf("  command: %s\n", statusLine.Command)
+		return 0
+	}
+
+	fmt.Printf("Installed status line in %s\n", path)
+	fmt.Printf("  command: %s\n", statusLine.Command)
+	if backup != "" {
+		fmt.Printf("  backup:  %s\n", backup)
+	}
+
+	return 0
+}
+
+// runUninstall removes the statusLine block from a Claude Code settings file
+func runUninstall(args []string) int {
+	fs := flag.NewFlagSet("uninstall", flag.ContinueOnError)
+	scope := fs.String("scope", string(settings.ScopeUser), "Settings file to edit: user, project or local")
+	projectDir := fs.String("project-dir", ".", "Project directory for the project and local scopes")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	path, err := settings.Path(settings.Scope(*scope), *projectDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 2
+	}
+
+	backup, changed, err := settings.Uninstall(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 1
+	}
+
+	if !changed {
+		fmt.Printf("No status line configured in %s\n", path)
+		return 0
+	}
+
+	fmt.Printf("Removed status line from %s\n", path)
+	fmt.Printf("  backup: %s\n", backup)
+	return 0
+}
+
+// statusLineCommand builds the command line Claude Code runs on every refresh
+func statusLineCommand(command, style, glyphs string) string {
+	if command == "" {
+		command = defaultCommand()
+	}
+
+	parts := []string{command}
+	if style != "" {
+		parts = append(parts, "--style", style)
+	}
+	if glyphs != "" {
+		parts = append(parts, "--glyphs", glyphs)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// defaultCommand prefers the bare binary name so settings survive reinstalls to another location
+func defaultCommand() string {
+	if _, err := exec.LookPath(binaryName); err == nil {
+		return binaryName
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return binaryName
+	}
+
+	// Quote paths with spaces since Claude Code runs the command through a shell
+	if strings.ContainsAny(executable, " \t") {
+		return fmt.Sprintf("%q", executable)
+	}
+	return executable
+}
@@ -1,40 +1,435 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
 
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/daemon"
+	"github.com/DieGopherLT/cc-status-line/debuglog"
 	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
 	"github.com/DieGopherLT/cc-status-line/metrics"
 	"github.com/DieGopherLT/cc-status-line/parser"
+	"github.com/DieGopherLT/cc-status-line/rules"
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
 )
 
-func main() {
-	style := flag.String("style", "classic", "Status line style: classic, gradient, compact")
-	flag.Parse()
+// renderOptions controls one run of the render pipeline
+type renderOptions struct {
+	Style      string
+	Glyphs     *formatters.GlyphSet
+	ParseMode  parser.ParseMode
+	DebugInput bool   // Log the raw hook input and its validation problems
+	DebugLog   string // Debug log path
+	Timings    string // Per-phase timing output: off, line or log
+	NoDaemon   bool   // Render in-process even when a daemon is running
+
+	Segments []string                    // Optional segments to show, from the config
+	Changes  string                      // "session" replaces the git diff with the edits segment, from the config
+	Commands []config.Command            // Custom command segments, from the config
+	Danger   map[string][]*regexp.Regexp // Danger patterns of the infrastructure segments, from the config
+	Rules    []string                    // Styling rules, from the config
+	Getenv   func(key string) string     // Environment of the process Claude Code started
+	Environ  []string                    // Environment custom commands run with; nil inherits this process's
+
+	ProtectedBranches []string  // Branch patterns that turn changes into a warning, from the config
+	ProtectedWarning  bool      // Also write that warning to Stderr
+	Stderr            io.Writer // Receives warnings; set by render
+
+	BlockTokenLimit int // Token budget of a usage block, 0 to infer it, from the config
 
-	run(*style)
+	// Hooks for the daemon, nil when rendering in-process
+	GitInfo      func(dir string) *metrics.GitInfo               // Replaces metrics.GetGitInfo, e.g. with a cache
+	Observe      func(*parser.StatusHook, *metrics.TokenMetrics) // Sees every successfully parsed hook
+	CommandCache *metrics.CommandCache                           // Replaces the command cache persisted on disk
+	UsageCache   *sessionlog.Cache                               // Replaces the usage cache persisted on disk
+}
+
+func main() {
+	os.Exit(dispatch(os.Args[1:]))
 }
 
-func run(style string) {
-	// Parse status hook JSON from stdin
-	hook, err := parser.ParseStatusHook(os.Stdin)
+// runRender is the default command: read the status hook from stdin and print the status line,
+// through the daemon when one is running
+func runRender(args []string) int {
+	// Validate flags before blocking on stdin; the defaults that count come from the config
+	// loaded below or by the daemon
+	check, err := parseRenderFlags(args, config.Default(), os.Getenv, os.Stderr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "cc-status-line error: %v\n", err)
+		return 2
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line error: failed to read status hook JSON: %v\n", err)
 		fmt.Println("Status: Error parsing input")
-		return
+		return 0
+	}
+
+	if !check.NoDaemon {
+		req := daemon.Request{Command: daemon.CommandRender, Args: args, Input: input, Env: os.Environ()}
+		if resp, err := daemon.Call(daemon.SocketPath(), req); err == nil {
+			fmt.Fprint(os.Stdout, resp.Stdout)
+			fmt.Fprint(os.Stderr, resp.Stderr)
+			return resp.ExitCode
+		}
+	}
+
+	opts, err := parseRenderFlags(args, withProjectConfig(loadConfig(), input, os.Stderr), os.Getenv, os.Stderr)
+	if err != nil {
+		return 2
+	}
+	opts.UsageCache = sessionlog.NewCache(sessionlog.DefaultCachePath())
+	code := render(input, opts, os.Stdout, os.Stderr)
+
+	// The status line is out; finish a log read that outlasted the render so the next
+	// process finds it
+	opts.UsageCache.Wait()
+	return code
+}
+
+// parseRenderFlags parses the render flags with defaults from cfg, resolving "auto" glyphs
+// from the environment seen through getenv. Errors and -h output go to output.
+func parseRenderFlags(args []string, cfg *config.Config, getenv func(string) string, output io.Writer) (renderOptions, error) {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(output)
+	style := fs.String("style", cfg.Style, "Status line style: classic, gradient, compact, minimal, nerd")
+	glyphs := fs.String("glyphs", cfg.Glyphs, "Glyph set: auto, unicode, nerdfont, ascii")
+	parseMode := fs.String("parse-mode", cfg.ParseMode, "Hook validation: lenient fills defaults, strict rejects incomplete input")
+	debugInput := fs.Bool("debug-input", false, "Append the raw hook JSON and validation problems to the debug log")
+	debugLog := fs.String("debug-log", debuglog.DefaultPath(), "Debug log path")
+	var timings timingsFlag
+	fs.Var(&timings, "timings", "Report per-phase durations: --timings appends a line, --timings=log writes to the debug log")
+	noDaemon := fs.Bool("no-daemon", false, "Render in-process even when a daemon is running")
+	if err := fs.Parse(args); err != nil {
+		return renderOptions{}, err
+	}
+	if !isGlyphSetName(*glyphs) {
+		err := fmt.Errorf("unknown glyph set %q (expected auto, unicode, nerdfont or ascii)", *glyphs)
+		fmt.Fprintf(output, "cc-status-line: %v\n", err)
+		return renderOptions{}, err
+	}
+	if mode := parser.ParseMode(*parseMode); mode != parser.ParseLenient && mode != parser.ParseStrict {
+		err := fmt.Errorf("unknown parse mode %q (expected lenient or strict)", *parseMode)
+		fmt.Fprintf(output, "cc-status-line: %v\n", err)
+		return renderOptions{}, err
+	}
+	// A bad pattern only loses its highlighting; doctor lists it too
+	danger, err := display.CompileDanger(cfg.Danger)
+	if err != nil {
+		fmt.Fprintf(output, "cc-status-line warning: %v\n", err)
+	}
+
+	return renderOptions{
+		Style:      *style,
+		Glyphs:     formatters.ResolveGlyphSetWith(*glyphs, getenv),
+		ParseMode:  parser.ParseMode(*parseMode),
+		DebugInput: *debugInput,
+		DebugLog:   *debugLog,
+		Timings:    string(timings),
+		NoDaemon:   *noDaemon,
+		Segments:   cfg.Segments,
+		Changes:    cfg.Changes,
+		Commands:   cfg.Commands,
+		Danger:     danger,
+		Rules:      cfg.Rules,
+
+		BlockTokenLimit: cfg.BlockTokenLimit,
+
+		ProtectedBranches: cfg.ProtectedBranches,
+		ProtectedWarning:  cfg.ProtectedWarning,
+		Getenv:            getenv,
+	}, nil
+}
+
+// render prints the status line for one hook payload. Input errors are reported in the
+// status line itself, so the exit code is always 0.
+func render(input []byte, opts renderOptions, stdout, stderr io.Writer) int {
+	opts.Stderr = stderr
+	statusLine, err := renderStatusLine(bytes.NewReader(input), opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "cc-status-line error: %v\n", err)
+		fmt.Fprintln(stdout, "Status: Error parsing input")
+		return 0
+	}
+
+	fmt.Fprintln(stdout, statusLine)
+	return 0
+}
+
+// renderStatusLine runs the full pipeline for one status hook payload
+func renderStatusLine(input io.Reader, opts renderOptions) (string, error) {
+	timer := newPhaseTimer()
+
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to read status hook JSON: %w", err)
+	}
+
+	// Parse status hook JSON
+	hook, problems, err := parseSafely(raw, opts)
+	timer.mark("parse")
+	if opts.DebugInput {
+		logInput(opts, raw, hook, problems, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Invalid rules are skipped; doctor reports them
+	styleRules, _ := rules.ParseAll(opts.Rules)
+	commandNames := make([]string, len(opts.Commands))
+	for i, command := range opts.Commands {
+		commandNames[i] = command.Name
+	}
+	opts.Segments = display.RuleSegments(opts.Segments, commandNames, styleRules)
+	sessionChanges := opts.Changes == "session"
+	if sessionChanges && !slices.Contains(opts.Segments, "edits") {
+		opts.Segments = append(opts.Segments, "edits")
+	}
+
+	getGitInfo := opts.GitInfo
+	if getGitInfo == nil {
+		getGitInfo = metrics.GetGitInfo
+	}
+
+	failed := make(map[string]bool)
+	onPanic := func(segment string, recovered any, stack []byte) {
+		logPanic(opts, segment, recovered, stack)
 	}
 
 	// Calculate token metrics (handles nil gracefully)
-	tokenMetrics := metrics.CalculateTokenMetrics(hook.ContextWindow)
+	tokenMetrics := collect("tokens", failed, onPanic, func() *metrics.TokenMetrics {
+		return metrics.CalculateTokenMetrics(hook.ContextWindow)
+	})
+	timer.mark("tokens")
 
 	// Get git information
-	gitInfo := metrics.GetGitInfo(hook.Workspace.CurrentDir)
+	gitInfo := collect("git", failed, onPanic, func() *metrics.GitInfo {
+		return getGitInfo(hook.Workspace.CurrentDir)
+	})
+	timer.mark("git")
+
+	// Optional data sources only run when their segment is enabled
+	var toolchains []metrics.Toolchain
+	if slices.Contains(opts.Segments, "toolchain") {
+		toolchains = collect("toolchain", failed, onPanic, func() []metrics.Toolchain {
+			return metrics.DetectToolchains(projectDir(hook), opts.getenv())
+		})
+		timer.mark("toolchain")
+	}
 
-	// Format and output status line using selected formatter
-	formatter := display.NewFormatter(style)
-	statusLine := formatter.Format(hook, tokenMetrics, gitInfo)
+	infra := make(map[string]*metrics.InfraContext)
+	for _, name := range opts.Segments {
+		if detect, ok := metrics.InfraDetectors[name]; ok {
+			infra[name] = collect(name, failed, onPanic, func() *metrics.InfraContext {
+				return detect(opts.getenv(), homeDir(opts.getenv()))
+			})
+			timer.mark(name)
+		}
+	}
+
+	commands := runCommands(hook, opts, failed)
+	if len(commands) > 0 {
+		timer.mark("commands")
+	}
+
+	usageTotals, block := collectUsage(opts, failed, onPanic)
+	if len(usageTotals) > 0 || block != nil {
+		timer.mark("usage")
+	}
+
+	protected := gitInfo != nil && gitInfo.HasChanges && metrics.IsProtectedBranch(gitInfo, opts.ProtectedBranches)
+	if protected && opts.ProtectedWarning && opts.Stderr != nil {
+		fmt.Fprintf(opts.Stderr, "cc-status-line warning: uncommitted changes on protected branch %s\n", gitInfo.Branch)
+	}
 
-	fmt.Println(statusLine)
+	if opts.Observe != nil {
+		opts.Observe(hook, tokenMetrics)
+	}
+
+	extraData := display.ExtraData{
+		Hook:       hook,
+		Home:       homeDir(opts.getenv()),
+		Toolchains: toolchains,
+		Commands:   commands,
+		Usage:      usageTotals,
+		Block:      block,
+		Infra:      infra,
+		Danger:     opts.Danger,
+		Protected:  protected,
+	}
+	effects := rules.Evaluate(styleRules, display.RuleValues(tokenMetrics, gitInfo, extraData))
+
+	// Format status line using selected formatter
+	formatterOpts := formatters.Options{
+		Glyphs:    opts.Glyphs,
+		Failed:    failed,
+		OnPanic:   onPanic,
+		Extra:     display.Extras(opts.Segments, extraData),
+		Overrides: display.Overrides(effects),
+
+		ProtectedBranch: protected,
+		HideGitDiff:     sessionChanges,
+	}
+	formatter := display.NewFormatter(opts.Style, formatterOpts)
+	statusLine := display.FormatSafely(formatter, formatterOpts, hook, tokenMetrics, gitInfo)
+	timer.mark("render")
+
+	return withTimings(statusLine, timer, opts), nil
+}
+
+// withTimings reports the phase durations according to opts.Timings
+func withTimings(statusLine string, timer *phaseTimer, opts renderOptions) string {
+	switch opts.Timings {
+	case timingsLine:
+		return statusLine + "\n" + timer.String()
+	case timingsLog:
+		if err := debuglog.Append(opts.DebugLog, "timings", timer.String()); err != nil {
+			opts.warn(err)
+		}
+	}
+	return statusLine
+}
+
+// parseSafely decodes the hook, reporting a parser panic as an ordinary error
+func parseSafely(raw []byte, opts renderOptions) (hook *parser.StatusHook, problems []string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logPanic(opts, "parser", recovered, debug.Stack())
+			hook, err = nil, fmt.Errorf("parser panic: %v", recovered)
+		}
+	}()
+
+	return parser.Decode(raw, opts.ParseMode)
+}
+
+// collect runs a metric collector, recovering panics so a broken data source only
+// replaces its own segments with an error marker
+func collect[T any](source string, failed map[string]bool, onPanic func(string, any, []byte), collector func() T) (result T) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			onPanic(source, recovered, debug.Stack())
+			display.MarkSourceFailed(failed, source)
+		}
+	}()
+
+	return collector()
+}
+
+// logPanic writes a recovered panic with its stack trace to the debug log
+func logPanic(opts renderOptions, segment string, recovered any, stack []byte) {
+	body := fmt.Sprintf("panic: %v\n\n%s", recovered, stack)
+	if err := debuglog.Append(opts.DebugLog, fmt.Sprintf("panic in %s", segment), body); err != nil {
+		opts.warn(err)
+	}
+}
+
+// logInput records the raw hook input and its problems for bug reports
+func logInput(opts renderOptions, raw []byte, hook *parser.StatusHook, problems []string, parseErr error) {
+	var body strings.Builder
+
+	if parseErr != nil {
+		fmt.Fprintf(&body, "error: %v\n", parseErr)
+	}
+	if len(problems) == 0 {
+		body.WriteString("problems: none\n")
+	} else {
+		body.WriteString("problems:\n")
+		for _, problem := range problems {
+			fmt.Fprintf(&body, "  - %s\n", problem)
+		}
+	}
+	if hook != nil {
+		fmt.Fprintf(&body, "claude code version: %s\n", hook.Schema())
+	}
+	if hook != nil && len(hook.Extra) > 0 {
+		extra, _ := json.Marshal(hook.Extra)
+		fmt.Fprintf(&body, "unknown fields: %s\n", extra)
+	}
+	fmt.Fprintf(&body, "input:\n%s\n", raw)
+
+	if err := debuglog.Append(opts.DebugLog, fmt.Sprintf("hook input (%s)", opts.ParseMode), body.String()); err != nil {
+		opts.warn(err)
+	}
+}
+
+// warn reports a non-fatal problem on opts.Stderr, which the daemon forwards to its client
+func (opts renderOptions) warn(err error) {
+	if opts.Stderr != nil {
+		fmt.Fprintf(opts.Stderr, "cc-status-line warning: %v\n", err)
+	}
+}
+
+// getenv returns the environment lookup for env-dependent segments
+func (opts renderOptions) getenv() func(string) string {
+	if opts.Getenv == nil {
+		return os.Getenv
+	}
+	return opts.Getenv
+}
+
+// usageCache returns the cache for usage read from the session logs: the daemon's or the
+// render's, or one persisted on disk
+func (opts renderOptions) usageCache() *sessionlog.Cache {
+	if opts.UsageCache == nil {
+		return sessionlog.NewCache(sessionlog.DefaultCachePath())
+	}
+	return opts.UsageCache
+}
+
+// commandCache returns the cache for command output: the daemon's, or the one persisted on disk
+func (opts renderOptions) commandCache() *metrics.CommandCache {
+	if opts.CommandCache == nil {
+		return metrics.NewCommandCache(metrics.DefaultCommandCachePath())
+	}
+	return opts.CommandCache
+}
+
+// projectDir is the directory project-level detection runs in
+func projectDir(hook *parser.StatusHook) string {
+	if hook.Workspace.ProjectDir != "" {
+		return hook.Workspace.ProjectDir
+	}
+	return hook.Workspace.CurrentDir
+}
+
+// homeDir returns the home directory from an environment, empty when unknown
+func homeDir(getenv func(string) string) string {
+	if home := getenv("HOME"); home != "" {
+		return home
+	}
+	return getenv("USERPROFILE")
+}
+
+// withProjectConfig layers the config files of the project the hook input comes from over
+// cfg. Project files that fail to load are reported on stderr and skipped.
+func withProjectConfig(cfg *config.Config, input []byte, stderr io.Writer) *config.Config {
+	// Invalid input is reported by the parser later; it simply has no project here
+	var hook parser.StatusHook
+	_ = json.Unmarshal(input, &hook)
+
+	layered, _, err := cfg.WithProject(projectDir(&hook))
+	if err != nil {
+		fmt.Fprintf(stderr, "cc-status-line warning: %v\n", err)
+	}
+	return layered
+}
+
+// loadConfig loads the user config, warning on stderr and falling back to defaults on failure
+func loadConfig() *config.Config {
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line warning: %v\n", err)
+	}
+	return cfg
 }
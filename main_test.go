@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+// BenchmarkRenderStatusLine measures the whole pipeline against the sample input,
+// including git, which runs in the sample's (nonexistent) workspace directory
+func BenchmarkRenderStatusLine(b *testing.B) {
+	input, err := os.ReadFile("status-line.json")
+	if err != nil {
+		b.Fatalf("failed to read sample input: %v", err)
+	}
+
+	opts := renderOptions{Style: "classic", Glyphs: formatters.UnicodeGlyphs, ParseMode: parser.ParseLenient}
+
+	for b.Loop() {
+		if _, err := renderStatusLine(bytes.NewReader(input), opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseRenderFlagsRejectsUnknownGlyphSet(t *testing.T) {
+	var output strings.Builder
+	if _, err := parseRenderFlags([]string{"--glyphs", "nerd"}, config.Default(), os.Getenv, &output); err == nil {
+		t.Fatal("parseRenderFlags() accepted an unknown glyph set")
+	}
+	if !strings.Contains(output.String(), `unknown glyph set "nerd"`) {
+		t.Errorf("output = %q, want the unknown glyph set reported", output.String())
+	}
+
+	opts, err := parseRenderFlags([]string{"--glyphs", "ascii"}, config.Default(), os.Getenv, &output)
+	if err != nil || opts.Glyphs != formatters.ASCIIGlyphs {
+		t.Errorf("parseRenderFlags(--glyphs ascii) = %v, %v", opts.Glyphs, err)
+	}
+}
+
+func TestParseRenderFlagsCompilesDanger(t *testing.T) {
+	cfg := config.Default()
+	cfg.Danger["kube"] = []string{"^live-", "(unclosed"}
+
+	var output strings.Builder
+	opts, err := parseRenderFlags(nil, cfg, os.Getenv, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `invalid danger pattern "(unclosed" for kube`) {
+		t.Errorf("output = %q, want the invalid pattern reported", output.String())
+	}
+	if len(opts.Danger["kube"]) != 1 || !opts.Danger["kube"][0].MatchString("live-eu") {
+		t.Errorf("Danger[kube] = %v, want the valid pattern kept", opts.Danger["kube"])
+	}
+}
+
+func TestParseRenderFlagsRejectsUnknownParseMode(t *testing.T) {
+	var output strings.Builder
+	if _, err := parseRenderFlags([]string{"--parse-mode", "loose"}, config.Default(), os.Getenv, &output); err == nil {
+		t.Fatal("parseRenderFlags() accepted an unknown parse mode")
+	}
+	if !strings.Contains(output.String(), `unknown parse mode "loose"`) {
+		t.Errorf("output = %q, want the unknown parse mode reported", output.String())
+	}
+
+	// A bad default from the config file is caught the same way
+	cfg := config.Default()
+	cfg.ParseMode = "Strict"
+	if _, err := parseRenderFlags(nil, cfg, os.Getenv, &output); err == nil {
+		t.Error("parseRenderFlags() accepted an unknown configured parse mode")
+	}
+
+	opts, err := parseRenderFlags([]string{"--parse-mode", "strict"}, config.Default(), os.Getenv, &output)
+	if err != nil || opts.ParseMode != parser.ParseStrict {
+		t.Errorf("parseRenderFlags(--parse-mode strict) = %v, %v", opts.ParseMode, err)
+	}
+}
+
+func TestRunPreviewRejectsUnknownNames(t *testing.T) {
+	t.Setenv(config.EnvPath, filepath.Join(t.TempDir(), "config.toml"))
+
+	for _, args := range [][]string{
+		{"--style", "bogus", "status-line.json"},
+		{"--glyphs", "bogus", "status-line.json"},
+		{"--style", "nerd", "--glyphs", "nerd", "status-line.json"},
+	} {
+		if code := runPreview(args); code != 2 {
+			t.Errorf("runPreview(%q) = %d, want 2", args, code)
+		}
+	}
+}
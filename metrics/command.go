@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/cachefile"
+)
+
+// commandCacheMaxAge is how long unused results stay in a persisted command cache
+const commandCacheMaxAge = 24 * time.Hour
+
+// CommandResult is the outcome of one custom command run
+type CommandResult struct {
+	Output string    `json:"output"`
+	Error  string    `json:"error,omitempty"`
+	RanAt  time.Time `json:"ran_at"`
+}
+
+// RunCommand runs line through the shell in dir and returns its trimmed standard output.
+// env replaces the environment when not nil. The command is killed after timeout.
+func RunCommand(line, dir string, env []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, line)
+	cmd.Dir = dir
+	cmd.Env = env
+	// Background children may keep the output pipe open after the shell is killed
+	cmd.WaitDelay = timeout / 4
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, firstOutputLine(message))
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// shellCommand runs line through the platform shell
+func shellCommand(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
+
+// ExtractOutput picks the text to show from command output. Without a pattern it is the
+// first non-empty line; with one it is the first capture group, or the whole match when
+// the pattern has no groups. ok is false when the pattern does not match.
+func ExtractOutput(output, pattern string) (text string, ok bool, err error) {
+	if pattern == "" {
+		return firstOutputLine(output), true, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid match pattern: %w", err)
+	}
+
+	match := re.FindStringSubmatch(output)
+	switch {
+	case match == nil:
+		return "", false, nil
+	case len(match) > 1:
+		return strings.TrimSpace(match[1]), true, nil
+	default:
+		return strings.TrimSpace(match[0]), true, nil
+	}
+}
+
+// firstOutputLine returns the first non-empty line of s
+func firstOutputLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CommandCache keeps command results for their TTL, failures included, so a slow or
+// broken command doesn't run on every render. With a path, results are persisted there
+// and shared between status line processes; without one they only live in memory.
+type CommandCache struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	results map[string]CommandResult
+}
+
+// NewCommandCache creates a cache persisted at path, or an in-memory cache if path is empty
+func NewCommandCache(path string) *CommandCache {
+	return &CommandCache{path: path, results: make(map[string]CommandResult)}
+}
+
+// DefaultCommandCachePath returns the command cache location inside the user cache directory
+func DefaultCommandCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "cc-status-line", "commands.json")
+}
+
+// Get returns the result cached under key when it is younger than ttl, otherwise it calls run
+// and caches its result
+func (c *CommandCache) Get(key string, ttl time.Duration, run func() (string, error)) (string, error) {
+	c.mu.Lock()
+	c.load()
+	result, ok := c.results[key]
+	c.mu.Unlock()
+
+	if !ok || time.Since(result.RanAt) >= ttl {
+		output, err := run()
+		result = CommandResult{Output: output, RanAt: time.Now()}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		c.mu.Lock()
+		c.results[key] = result
+		c.save()
+		c.mu.Unlock()
+	}
+
+	if result.Error != "" {
+		return "", errors.New(result.Error)
+	}
+	return result.Output, nil
+}
+
+// load reads the persisted results once. A missing or corrupt file starts an empty cache.
+func (c *CommandCache) load() {
+	if c.loaded || c.path == "" {
+		return
+	}
+	c.loaded = true
+
+	_ = cachefile.Load(c.path, &c.results)
+	if c.results == nil {
+		c.results = make(map[string]CommandResult)
+	}
+}
+
+// save persists the results, dropping the ones unused for a day. Errors are ignored: a lost
+// cache only means commands run again.
+func (c *CommandCache) save() {
+	if c.path == "" {
+		return
+	}
+
+	for key, entry := range c.results {
+		if time.Since(entry.RanAt) > commandCacheMaxAge {
+			delete(c.results, key)
+		}
+	}
+	_ = cachefile.Save(c.path, c.results)
+}
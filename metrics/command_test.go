@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use sh")
+	}
+	dir := t.TempDir()
+
+	output, err := RunCommand("echo '  hello  '; pwd", dir, nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "hello  \n" + dir; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	_, err = RunCommand("echo broken >&2; exit 3", dir, nil, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("failing command: err = %v, want the stderr message", err)
+	}
+
+	start := time.Now()
+	_, err = RunCommand("sleep 5", dir, nil, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow command: err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("slow command took %s to time out", elapsed)
+	}
+}
+
+func TestExtractOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		pattern string
+		want    string
+		ok      bool
+	}{
+		{name: "first line", output: "\n  prod-cluster \nsecond\n", want: "prod-cluster", ok: true},
+		{name: "whole match", output: "version: 1.4.2", pattern: `\d+\.\d+\.\d+`, want: "1.4.2", ok: true},
+		{name: "first group", output: "Current: v2 (stable)", pattern: `Current: (\S+)`, want: "v2", ok: true},
+		{name: "no match", output: "nothing here", pattern: `\d+`, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ExtractOutput(tt.output, tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ExtractOutput() = %q, %v; want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if _, _, err := ExtractOutput("x", "("); err == nil {
+		t.Error("invalid pattern: expected an error")
+	}
+}
+
+func TestCommandCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.json")
+	runs := 0
+	run := func() (string, error) {
+		runs++
+		if runs == 2 {
+			return "", errors.New("boom")
+		}
+		return "out", nil
+	}
+
+	cache := NewCommandCache(path)
+	for range 2 {
+		if output, err := cache.Get("key", time.Hour, run); output != "out" || err != nil {
+			t.Fatalf("Get() = %q, %v", output, err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("command ran %d times within the TTL, want 1", runs)
+	}
+
+	// Another process sees the persisted result
+	if output, _ := NewCommandCache(path).Get("key", time.Hour, run); output != "out" || runs != 1 {
+		t.Errorf("persisted cache: output %q after %d runs", output, runs)
+	}
+
+	// Expired results run again, and failures are cached like output
+	if _, err := cache.Get("key", 0, run); err == nil || err.Error() != "boom" {
+		t.Errorf("expired entry: err = %v, want boom", err)
+	}
+	if _, err := cache.Get("key", time.Hour, run); err == nil || runs != 2 {
+		t.Errorf("cached failure: err = %v after %d runs", err, runs)
+	}
+}
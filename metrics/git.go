@@ -3,6 +3,8 @@ package metrics
 import (
 	"fmt"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -11,23 +13,74 @@ import (
 type GitInfo struct {
 	Branch        string
 	BranchDisplay string
+	Detached      bool   // HEAD does not point at a branch
+	Tag           string // Tag pointing exactly at a detached HEAD
+	Commit        string // Abbreviated commit hash of a detached HEAD
 	HasChanges    bool
 	ChangesText   string
 	IsGitRepo     bool
 	Additions     int
 	Deletions     int
+
+	// Files by state, from git status
+	Staged    int // Changed in the index
+	Modified  int // Changed in the working tree but not staged, deletions included
+	Untracked int // Not tracked and not ignored; an untracked directory counts once
+	Conflicts int // Unmerged after a conflicting merge, rebase or cherry-pick
+
+	Worktree        string // Name of the linked worktree the directory is in, empty in the main worktree
+	MainRepo        string // Repository a linked worktree belongs to
+	Superproject    string // Superproject name when the directory is inside a submodule
+	Submodule       string // Path of that submodule inside its superproject
+	DirtySubmodules int    // Submodules with new commits, local changes or untracked files
+}
+
+// GitProvider runs git commands in a directory and returns their standard output.
+// Tests substitute a scripted fake (see metrics/gittest).
+type GitProvider interface {
+	Run(dir string, args ...string) ([]byte, error)
+}
+
+// ExecGit runs the git binary found in PATH
+type ExecGit struct{}
+
+// Run executes git with args in dir
+func (ExecGit) Run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
+
+// DefaultGitProvider is the provider used by GetGitInfo
+var DefaultGitProvider GitProvider = ExecGit{}
+
+// IsProtectedBranch reports whether a checked out branch matches one of the glob patterns,
+// e.g. "main" or "release/*". A detached HEAD is never protected.
+func IsProtectedBranch(gitInfo *GitInfo, patterns []string) bool {
+	if gitInfo == nil || !gitInfo.IsGitRepo || gitInfo.Detached {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, gitInfo.Branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
 }
 
 // GetGitInfo extracts git branch and change information
 func GetGitInfo(cwd string) *GitInfo {
+	return GetGitInfoWith(DefaultGitProvider, cwd)
+}
+
+// GetGitInfoWith extracts git branch and change information using the given provider
+func GetGitInfoWith(git GitProvider, cwd string) *GitInfo {
 	info := &GitInfo{
 		IsGitRepo: false,
 	}
 
 	// Try to get the current branch
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = cwd
-	output, err := cmd.Output()
+	output, err := git.Run(cwd, "rev-parse", "--abbrev-ref", "HEAD")
 
 	if err != nil {
 		// Not in a git repository
@@ -40,11 +93,29 @@ func GetGitInfo(cwd string) *GitInfo {
 	info.Branch = strings.TrimSpace(string(output))
 	info.BranchDisplay = info.Branch
 
+	// rev-parse reports a detached HEAD as the literal "HEAD"
+	if info.Branch == "HEAD" {
+		info.Detached = true
+		info.Tag = getExactTag(git, cwd)
+		info.Commit = getShortCommit(git, cwd)
+
+		switch {
+		case info.Tag != "":
+			info.BranchDisplay = info.Tag
+		case info.Commit != "":
+			info.BranchDisplay = info.Commit
+		}
+	}
+
+	getLayout(git, cwd, info)
+
 	// Get git changes (staged + unstaged) from git directly
-	linesAdded, linesRemoved := getGitChanges(cwd)
+	linesAdded, linesRemoved := getGitChanges(git, cwd)
 	info.Additions = linesAdded
 	info.Deletions = linesRemoved
 
+	getFileStates(git, cwd, info)
+
 	// Format changes
 	if linesAdded > 0 || linesRemoved > 0 {
 		info.HasChanges = true
@@ -56,12 +127,140 @@ func GetGitInfo(cwd string) *GitInfo {
 	return info
 }
 
+// getLayout fills in where the checkout sits: a linked worktree, a submodule, or a
+// superproject with submodules of its own
+func getLayout(git GitProvider, cwd string, info *GitInfo) {
+	// --show-superproject-working-tree prints nothing outside a submodule, so it goes last
+	output, err := git.Run(cwd, "rev-parse", "--absolute-git-dir", "--git-common-dir", "--show-toplevel", "--show-superproject-working-tree")
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 3 {
+		return
+	}
+
+	gitDir, commonDir, topLevel := filepath.Clean(lines[0]), lines[1], filepath.Clean(lines[2])
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(cwd, commonDir)
+	}
+	commonDir = filepath.Clean(commonDir)
+
+	// Linked worktrees keep their own HEAD and index in <common dir>/worktrees/<name>
+	if gitDir != commonDir {
+		info.Worktree = filepath.Base(gitDir)
+		info.MainRepo = repoName(commonDir)
+	}
+
+	if len(lines) > 3 {
+		superproject := filepath.Clean(lines[3])
+		info.Superproject = filepath.Base(superproject)
+		if rel, err := filepath.Rel(superproject, topLevel); err == nil {
+			info.Submodule = filepath.ToSlash(rel)
+		}
+	}
+
+	// Skip the extra status scan in the common case of a repository without submodules
+	if hasSubmodules(git, cwd) {
+		info.DirtySubmodules = countDirtySubmodules(git, cwd)
+	}
+}
+
+// hasSubmodules reports whether the repository tracks a .gitmodules file at its top level
+func hasSubmodules(git GitProvider, cwd string) bool {
+	output, err := git.Run(cwd, "ls-files", "--", ":/.gitmodules")
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// repoName derives a repository name from its common git directory:
+// "/src/app/.git" and the bare "/src/app.git" both give "app"
+func repoName(commonDir string) string {
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Base(filepath.Dir(commonDir))
+	}
+	return strings.TrimSuffix(filepath.Base(commonDir), ".git")
+}
+
+// countDirtySubmodules counts submodules whose state differs from the recorded commit
+func countDirtySubmodules(git GitProvider, cwd string) int {
+	output, err := git.Run(cwd, "status", "--porcelain=v2", "--untracked-files=no", "--ignore-submodules=none")
+	if err != nil {
+		return 0
+	}
+
+	// Changed entries look like "1 .M S.M. <modes> <hashes> <path>"; the third field is
+	// "N..." for files and "S<commit><modified><untracked>" for submodules
+	dirty := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "1" && fields[0] != "2" {
+			continue
+		}
+		if strings.HasPrefix(fields[2], "S") && fields[2] != "S..." {
+			dirty++
+		}
+	}
+	return dirty
+}
+
+// getFileStates counts the files in each state git status reports
+func getFileStates(git GitProvider, cwd string, info *GitInfo) {
+	// Without optional locks, status doesn't refresh the index, which would invalidate a
+	// GitCache watching it
+	output, err := git.Run(cwd, "--no-optional-locks", "status", "--porcelain=v2")
+	if err != nil {
+		return
+	}
+
+	// Changed entries look like "1 .M N... <modes> <hashes> <path>", where the second field
+	// holds the index and working tree states, "." when unchanged. Submodules ("S..." in the
+	// third field) are left to the dirty submodule count.
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "?":
+			info.Untracked++
+		case "u":
+			info.Conflicts++
+		case "1", "2":
+			if len(fields) < 3 || len(fields[1]) != 2 || strings.HasPrefix(fields[2], "S") {
+				continue
+			}
+			if fields[1][0] != '.' {
+				info.Staged++
+			}
+			if fields[1][1] != '.' {
+				info.Modified++
+			}
+		}
+	}
+}
+
+// getExactTag returns the tag pointing at HEAD, or an empty string
+func getExactTag(git GitProvider, cwd string) string {
+	output, err := git.Run(cwd, "describe", "--tags", "--exact-match", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// getShortCommit returns the abbreviated hash of HEAD, or an empty string
+func getShortCommit(git GitProvider, cwd string) string {
+	output, err := git.Run(cwd, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // getGitChanges gets the number of lines added and removed from git
-func getGitChanges(cwd string) (int, int) {
+func getGitChanges(git GitProvider, cwd string) (int, int) {
 	// Get all changes (staged + unstaged) compared to HEAD
-	cmd := exec.Command("git", "diff", "--numstat", "HEAD")
-	cmd.Dir = cwd
-	output, err := cmd.Output()
+	output, err := git.Run(cwd, "diff", "--numstat", "HEAD")
 
 	if err != nil {
 		return 0, 0
@@ -0,0 +1,395 @@
+package metrics
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/metrics/gittest"
+)
+
+var errGit = errors.New("exit status 128")
+
+func TestGetGitInfoWithFake(t *testing.T) {
+	tests := []struct {
+		name   string
+		script func(f *gittest.Fake)
+		want   GitInfo
+	}{
+		{
+			name:   "not a repository",
+			script: func(f *gittest.Fake) { f.Fail("rev-parse --abbrev-ref HEAD", errGit) },
+			want:   GitInfo{BranchDisplay: "(no git)", ChangesText: "(no git)"},
+		},
+		{
+			name: "clean branch",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").On("diff --numstat HEAD", "")
+			},
+			want: GitInfo{Branch: "main", BranchDisplay: "main", ChangesText: "(no changes)", IsGitRepo: true},
+		},
+		{
+			name: "additions and deletions",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").
+					On("diff --numstat HEAD", "10\t2\ta.go\n5\t0\tdir/b.go\n")
+			},
+			want: GitInfo{
+				Branch: "main", BranchDisplay: "main", IsGitRepo: true,
+				HasChanges: true, ChangesText: "(+15 -2)", Additions: 15, Deletions: 2,
+			},
+		},
+		{
+			name: "additions only",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").On("diff --numstat HEAD", "3\t0\ta.go\n")
+			},
+			want: GitInfo{
+				Branch: "main", BranchDisplay: "main", IsGitRepo: true,
+				HasChanges: true, ChangesText: "(+3)", Additions: 3,
+			},
+		},
+		{
+			name: "deletions only",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").On("diff --numstat HEAD", "0\t4\ta.go\n")
+			},
+			want: GitInfo{
+				Branch: "main", BranchDisplay: "main", IsGitRepo: true,
+				HasChanges: true, ChangesText: "(-4)", Deletions: 4,
+			},
+		},
+		{
+			name: "binary files and malformed lines are skipped",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").
+					On("diff --numstat HEAD", "-\t-\timage.png\n\ngarbage\nx\ty\tbad.go\n1\t1\tfile with spaces.txt\n")
+			},
+			want: GitInfo{
+				Branch: "main", BranchDisplay: "main", IsGitRepo: true,
+				HasChanges: true, ChangesText: "(+1 -1)", Additions: 1, Deletions: 1,
+			},
+		},
+		{
+			name: "diff failure reports no changes",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").Fail("diff --numstat HEAD", errGit)
+			},
+			want: GitInfo{Branch: "main", BranchDisplay: "main", ChangesText: "(no changes)", IsGitRepo: true},
+		},
+		{
+			name: "file states",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "main\n").
+					On("diff --numstat HEAD", "").
+					On("--no-optional-locks status --porcelain=v2", strings.Join([]string{
+						"1 M. N... 100644 100644 100644 aaa bbb staged.go",
+						"1 .M N... 100644 100644 100644 aaa aaa modified.go",
+						"1 MD N... 100644 100644 000000 aaa bbb both.go",
+						"2 R. N... 100644 100644 100644 aaa aaa R100 new.go\told.go",
+						"1 .M SC.. 160000 160000 160000 aaa aaa libs/ui",
+						"u UU N... 100644 100644 100644 100644 aaa bbb ccc conflict.go",
+						"? notes.txt",
+						"? build/",
+						"! ignored.log",
+					}, "\n")+"\n")
+			},
+			want: GitInfo{
+				Branch: "main", BranchDisplay: "main", ChangesText: "(no changes)", IsGitRepo: true,
+				Staged: 3, Modified: 2, Untracked: 2, Conflicts: 1,
+			},
+		},
+		{
+			name: "detached at tag",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "HEAD\n").
+					On("describe --tags --exact-match HEAD", "v1.2.0\n").
+					On("rev-parse --short HEAD", "abc1234\n").
+					On("diff --numstat HEAD", "")
+			},
+			want: GitInfo{
+				Branch: "HEAD", BranchDisplay: "v1.2.0", Detached: true, Tag: "v1.2.0", Commit: "abc1234",
+				ChangesText: "(no changes)", IsGitRepo: true,
+			},
+		},
+		{
+			name: "detached without tag",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "HEAD\n").
+					Fail("describe --tags --exact-match HEAD", errGit).
+					On("rev-parse --short HEAD", "abc1234\n").
+					On("diff --numstat HEAD", "")
+			},
+			want: GitInfo{
+				Branch: "HEAD", BranchDisplay: "abc1234", Detached: true, Commit: "abc1234",
+				ChangesText: "(no changes)", IsGitRepo: true,
+			},
+		},
+		{
+			name: "detached with unreadable commit",
+			script: func(f *gittest.Fake) {
+				f.On("rev-parse --abbrev-ref HEAD", "HEAD\n").
+					Fail("describe --tags --exact-match HEAD", errGit).
+					Fail("rev-parse --short HEAD", errGit).
+					On("diff --numstat HEAD", "")
+			},
+			want: GitInfo{
+				Branch: "HEAD", BranchDisplay: "HEAD", Detached: true,
+				ChangesText: "(no changes)", IsGitRepo: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gittest.NewFake()
+			tt.script(fake)
+
+			got := GetGitInfoWith(fake, "/repo")
+			if *got != tt.want {
+				t.Errorf("GetGitInfoWith() = %+v, want %+v (calls: %v)", *got, tt.want, fake.Calls())
+			}
+		})
+	}
+}
+
+func TestGetGitInfoRepositoryStates(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(r *gittest.Repo)
+		branch   string
+		detached bool
+		added    int
+		removed  int
+	}{
+		{name: "clean", setup: func(r *gittest.Repo) {}, branch: "main"},
+		{name: "dirty", setup: (*gittest.Repo).MakeDirty, branch: "main", added: 2},
+		{name: "staged", setup: (*gittest.Repo).MakeStaged, branch: "main", added: 1, removed: 1},
+		{name: "untracked files are not counted", setup: (*gittest.Repo).MakeUntracked, branch: "main"},
+		{name: "binary change", setup: (*gittest.Repo).MakeBinaryChange, branch: "main"},
+		{name: "rename", setup: (*gittest.Repo).MakeRename, branch: "main"},
+		// Conflict markers and both sides count as additions against HEAD
+		{name: "merge conflict", setup: (*gittest.Repo).MakeConflict, branch: "main", added: 4},
+		{name: "detached", setup: (*gittest.Repo).MakeDetached, branch: "HEAD", detached: true},
+		{name: "rebase in progress", setup: (*gittest.Repo).MakeRebaseInProgress, branch: "HEAD", detached: true, added: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.NewRepo(t)
+			tt.setup(repo)
+
+			got := GetGitInfo(repo.Dir)
+			if !got.IsGitRepo {
+				t.Fatalf("IsGitRepo = false, want true")
+			}
+			if got.Branch != tt.branch || got.Detached != tt.detached {
+				t.Errorf("Branch = %q (detached %t), want %q (detached %t)", got.Branch, got.Detached, tt.branch, tt.detached)
+			}
+			if got.Additions != tt.added || got.Deletions != tt.removed {
+				t.Errorf("changes = +%d -%d, want +%d -%d", got.Additions, got.Deletions, tt.added, tt.removed)
+			}
+			if got.HasChanges != (tt.added > 0 || tt.removed > 0) {
+				t.Errorf("HasChanges = %t with +%d -%d", got.HasChanges, got.Additions, got.Deletions)
+			}
+		})
+	}
+}
+
+func TestGetGitInfoFileStates(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *gittest.Repo)
+		want  [4]int // Staged, modified, untracked, conflicts
+	}{
+		{name: "clean", setup: func(r *gittest.Repo) {}},
+		{name: "dirty", setup: (*gittest.Repo).MakeDirty, want: [4]int{0, 1, 0, 0}},
+		{name: "staged", setup: (*gittest.Repo).MakeStaged, want: [4]int{1, 0, 0, 0}},
+		{name: "untracked", setup: (*gittest.Repo).MakeUntracked, want: [4]int{0, 0, 1, 0}},
+		{name: "rename", setup: (*gittest.Repo).MakeRename, want: [4]int{1, 0, 0, 0}},
+		{name: "merge conflict", setup: (*gittest.Repo).MakeConflict, want: [4]int{0, 0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.NewRepo(t)
+			tt.setup(repo)
+
+			got := GetGitInfo(repo.Dir)
+			if states := [4]int{got.Staged, got.Modified, got.Untracked, got.Conflicts}; states != tt.want {
+				t.Errorf("staged, modified, untracked, conflicts = %v, want %v", states, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGitInfoDetachedAtTag(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	repo.Tag("v1.0.0")
+	repo.MakeDetached()
+
+	got := GetGitInfo(repo.Dir)
+	if got.Tag != "v1.0.0" || got.BranchDisplay != "v1.0.0" {
+		t.Errorf("Tag = %q, BranchDisplay = %q, want v1.0.0", got.Tag, got.BranchDisplay)
+	}
+	if got.Commit == "" {
+		t.Error("Commit is empty for a detached HEAD")
+	}
+}
+
+func TestGetGitInfoOutsideRepository(t *testing.T) {
+	got := GetGitInfo(t.TempDir())
+	if got.IsGitRepo || got.BranchDisplay != "(no git)" {
+		t.Errorf("GetGitInfo() = %+v, want no git", *got)
+	}
+}
+
+func BenchmarkGetGitInfo(b *testing.B) {
+	b.Run("fake", func(b *testing.B) {
+		fake := gittest.NewFake().
+			On("rev-parse --abbrev-ref HEAD", "main\n").
+			On("diff --numstat HEAD", strings.Repeat("12\t3\tsome/file.go\n", 200))
+
+		b.ReportAllocs()
+		for b.Loop() {
+			GetGitInfoWith(fake, "/repo")
+		}
+	})
+
+	b.Run("repository", func(b *testing.B) {
+		repo := gittest.NewRepo(b)
+		repo.MakeDirty()
+
+		for b.Loop() {
+			GetGitInfo(repo.Dir)
+		}
+	})
+}
+
+func TestGetLayoutWithFake(t *testing.T) {
+	const layout = "rev-parse --absolute-git-dir --git-common-dir --show-toplevel --show-superproject-working-tree"
+	const gitmodules = "ls-files -- :/.gitmodules"
+
+	tests := []struct {
+		name   string
+		script func(f *gittest.Fake)
+		want   GitInfo
+	}{
+		{
+			name: "main worktree",
+			script: func(f *gittest.Fake) {
+				f.On(layout, "/src/app/.git\n.git\n/src/app\n").On(gitmodules, "")
+			},
+		},
+		{
+			name: "linked worktree",
+			script: func(f *gittest.Fake) {
+				f.On(layout, "/src/app/.git/worktrees/feature-x\n/src/app/.git\n/src/feature-x\n").On(gitmodules, "")
+			},
+			want: GitInfo{Worktree: "feature-x", MainRepo: "app"},
+		},
+		{
+			name: "worktree of a bare repository",
+			script: func(f *gittest.Fake) {
+				f.On(layout, "/src/app.git/worktrees/main\n/src/app.git\n/src/main\n").On(gitmodules, "")
+			},
+			want: GitInfo{Worktree: "main", MainRepo: "app"},
+		},
+		{
+			name: "submodule",
+			script: func(f *gittest.Fake) {
+				f.On(layout, "/src/app/.git/modules/libs/core\n/src/app/.git/modules/libs/core\n/src/app/libs/core\n/src/app\n").
+					On(gitmodules, "")
+			},
+			want: GitInfo{Superproject: "app", Submodule: "libs/core"},
+		},
+		{
+			name: "superproject with dirty submodules",
+			script: func(f *gittest.Fake) {
+				f.On(layout, "/src/app/.git\n.git\n/src/app\n").
+					On(gitmodules, ".gitmodules\n").
+					On("status --porcelain=v2 --untracked-files=no --ignore-submodules=none",
+						"1 .M S.M. 160000 160000 160000 a b libs/core\n"+
+							"1 .M SC.. 160000 160000 160000 a b libs/ui\n"+
+							"1 .M S... 160000 160000 160000 a b libs/clean\n"+
+							"1 .M N... 100644 100644 100644 a b README.md\n")
+			},
+			want: GitInfo{DirtySubmodules: 2},
+		},
+		{
+			name:   "rev-parse failure leaves the layout empty",
+			script: func(f *gittest.Fake) { f.Fail(layout, errGit) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gittest.NewFake()
+			tt.script(fake)
+
+			var got GitInfo
+			getLayout(fake, "/src/app", &got)
+			if got != tt.want {
+				t.Errorf("getLayout() = %+v, want %+v (calls: %v)", got, tt.want, fake.Calls())
+			}
+		})
+	}
+}
+
+func TestGetGitInfoLinkedWorktree(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	worktree := repo.AddWorktree("feature-x")
+
+	got := GetGitInfo(worktree.Dir)
+	if got.Worktree != "feature-x" || got.MainRepo != filepath.Base(repo.Dir) || got.Branch != "feature-x" {
+		t.Errorf("Worktree = %q, MainRepo = %q, Branch = %q, want feature-x in %s", got.Worktree, got.MainRepo, got.Branch, filepath.Base(repo.Dir))
+	}
+
+	if main := GetGitInfo(repo.Dir); main.Worktree != "" || main.MainRepo != "" {
+		t.Errorf("main worktree reported as linked: %+v", *main)
+	}
+}
+
+func TestGetGitInfoSubmodules(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	clean := repo.AddSubmodule("libs/clean")
+	dirty := repo.AddSubmodule("libs/dirty")
+	dirty.MakeDirty()
+
+	got := GetGitInfo(repo.Dir)
+	if got.DirtySubmodules != 1 || got.Superproject != "" {
+		t.Errorf("DirtySubmodules = %d, Superproject = %q, want 1 dirty submodule and no superproject", got.DirtySubmodules, got.Superproject)
+	}
+
+	inside := GetGitInfo(dirty.Dir)
+	if inside.Superproject != filepath.Base(repo.Dir) || inside.Submodule != "libs/dirty" || !inside.HasChanges {
+		t.Errorf("Superproject = %q, Submodule = %q, HasChanges = %t, want a dirty libs/dirty in %s",
+			inside.Superproject, inside.Submodule, inside.HasChanges, filepath.Base(repo.Dir))
+	}
+
+	if got := GetGitInfo(clean.Dir); got.HasChanges || got.Submodule != "libs/clean" {
+		t.Errorf("clean submodule: %+v", *got)
+	}
+}
+
+func TestIsProtectedBranch(t *testing.T) {
+	patterns := []string{"main", "master", "release/*"}
+	tests := []struct {
+		info *GitInfo
+		want bool
+	}{
+		{info: &GitInfo{IsGitRepo: true, Branch: "main"}, want: true},
+		{info: &GitInfo{IsGitRepo: true, Branch: "release/2.1"}, want: true},
+		{info: &GitInfo{IsGitRepo: true, Branch: "feature/main"}, want: false},
+		{info: &GitInfo{IsGitRepo: true, Branch: "release"}, want: false},
+		{info: &GitInfo{IsGitRepo: true, Branch: "main", Detached: true}, want: false},
+		{info: &GitInfo{}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsProtectedBranch(tt.info, patterns); got != tt.want {
+			t.Errorf("IsProtectedBranch(%+v) = %v, want %v", tt.info, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package metrics
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/fswatch"
+)
+
+// MaxGitWatches is the default number of directories a GitCache watches per repository.
+// Larger repositories only get their git metadata watched and fall back to the TTL.
+const MaxGitWatches = 4096
+
+// GitCache serves GetGitInfo results from memory for long-lived processes. With a watcher,
+// entries live until HEAD, the index, a ref or a file in a tracked directory changes;
+// without one, or when a repository is too large to watch, they expire after the TTL.
+type GitCache struct {
+	MaxWatches int // Directories watched per repository before falling back to the TTL
+
+	git     GitProvider
+	watcher *fswatch.Watcher
+	ttl     time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]gitEntry     // Keyed by the directory GitInfo was collected in
+	repos      map[string]*watchedRepo // Keyed by git directory
+	generation uint64                  // Bumped on every invalidation to discard results collected meanwhile
+}
+
+type gitEntry struct {
+	info    *GitInfo
+	repo    *watchedRepo // nil outside a repository or without a watcher
+	fetched time.Time
+}
+
+// watchedRepo tracks the watches of one repository or linked worktree
+type watchedRepo struct {
+	gitDir    string // Per-worktree metadata: HEAD and index
+	commonDir string // Shared metadata: refs and packed-refs
+	topLevel  string
+
+	watched  map[string]bool // Directories with an active watch
+	tracked  map[string]bool // Absolute paths of tracked files and the directories holding them
+	complete bool            // Every tracked directory is watched, so entries never expire
+	resync   bool            // HEAD, the index or refs changed, so tracked may be outdated
+}
+
+// NewGitCache creates a cache running git through provider. watcher may be nil.
+func NewGitCache(provider GitProvider, watcher *fswatch.Watcher, ttl time.Duration) *GitCache {
+	return &GitCache{
+		MaxWatches: MaxGitWatches,
+		git:        provider,
+		watcher:    watcher,
+		ttl:        ttl,
+		entries:    make(map[string]gitEntry),
+		repos:      make(map[string]*watchedRepo),
+	}
+}
+
+// Get returns the git state of dir, running git only when the cached entry is stale
+func (c *GitCache) Get(dir string) *GitInfo {
+	c.mu.Lock()
+	entry, ok := c.entries[dir]
+	if ok && (entry.repo != nil && entry.repo.complete || time.Since(entry.fetched) < c.ttl) {
+		c.mu.Unlock()
+		return entry.info
+	}
+	c.mu.Unlock()
+
+	// Watches go up before collecting, so a change made meanwhile is never missed
+	repo := c.syncRepo(dir)
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	entry = gitEntry{info: GetGitInfoWith(c.git, dir), repo: repo, fetched: time.Now()}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[dir] = entry
+	}
+	c.mu.Unlock()
+
+	return entry.info
+}
+
+// Len returns the number of cached directories
+func (c *GitCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// syncRepo makes sure the repository containing dir is watched, returning nil when dir
+// is not in a repository or nothing can be watched
+func (c *GitCache) syncRepo(dir string) *watchedRepo {
+	if c.watcher == nil {
+		return nil
+	}
+
+	output, err := c.git.Run(dir, "rev-parse", "--absolute-git-dir", "--git-common-dir", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 {
+		return nil
+	}
+	gitDir, commonDir, topLevel := filepath.Clean(lines[0]), lines[1], filepath.Clean(lines[2])
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	commonDir = filepath.Clean(commonDir)
+
+	c.mu.Lock()
+	repo, ok := c.repos[gitDir]
+	if ok && !repo.resync {
+		c.mu.Unlock()
+		return repo
+	}
+	if !ok {
+		repo = &watchedRepo{gitDir: gitDir, commonDir: commonDir, topLevel: topLevel, watched: make(map[string]bool)}
+		c.repos[gitDir] = repo
+	}
+	repo.resync = false
+	c.mu.Unlock()
+
+	metadata := c.metadataDirs(repo)
+	tracked, trackedDirs, listed := c.trackedPaths(topLevel)
+	complete := listed && len(metadata)+len(trackedDirs) <= c.MaxWatches
+	// Edits inside submodules happen in other repositories, so their dirty state needs the TTL
+	if _, err := os.Stat(filepath.Join(topLevel, ".gitmodules")); err == nil {
+		complete = false
+	}
+	if !complete {
+		trackedDirs = nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	want := make(map[string]func(string), len(metadata)+len(trackedDirs))
+	for _, path := range metadata {
+		want[path] = c.metadataChanged
+	}
+	for _, path := range trackedDirs {
+		want[path] = c.worktreeChanged
+	}
+
+	for path := range repo.watched {
+		if want[path] == nil {
+			c.watcher.Unwatch(path)
+			delete(repo.watched, path)
+		}
+	}
+	for path, handler := range want {
+		if repo.watched[path] {
+			continue
+		}
+		if err := c.watcher.Watch(path, handler); err != nil {
+			// Typically exhausted inotify limits; the TTL keeps results from going stale forever
+			complete = false
+			continue
+		}
+		repo.watched[path] = true
+	}
+
+	repo.tracked = tracked
+	repo.complete = complete
+	return repo
+}
+
+// metadataDirs lists the git directories whose entries change on commits, checkouts,
+// staging and ref updates
+func (c *GitCache) metadataDirs(repo *watchedRepo) []string {
+	dirs := []string{repo.gitDir}
+	if repo.commonDir != repo.gitDir {
+		dirs = append(dirs, repo.commonDir)
+	}
+
+	// Branch names may contain slashes, so refs is a tree of directories
+	_ = filepath.WalkDir(filepath.Join(repo.commonDir, "refs"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	return dirs
+}
+
+// trackedPaths lists the tracked files of the worktree at topLevel and the directories
+// holding them. git status lists an untracked directory as one entry, so what happens inside
+// it changes nothing shown and it needs no watch.
+func (c *GitCache) trackedPaths(topLevel string) (tracked map[string]bool, dirs []string, ok bool) {
+	output, err := c.git.Run(topLevel, "ls-files", "-z")
+	if err != nil {
+		return nil, nil, false
+	}
+
+	tracked = map[string]bool{topLevel: true}
+	dirs = []string{topLevel}
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(topLevel, filepath.FromSlash(name))
+		tracked[path] = true
+
+		for dir := filepath.Dir(path); !tracked[dir]; dir = filepath.Dir(dir) {
+			tracked[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return tracked, dirs, true
+}
+
+// metadataChanged handles events in git directories
+func (c *GitCache) metadataChanged(changed string) {
+	// Lock files come and go around every write; the rename that follows is the real change
+	if strings.HasSuffix(changed, ".lock") {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, repo := range c.repos {
+		if within(changed, repo.gitDir) || within(changed, repo.commonDir) {
+			repo.resync = true
+			c.invalidate(repo)
+		}
+	}
+}
+
+// worktreeChanged handles events in working tree directories. Any file appearing next to
+// tracked ones may change the untracked count, so events aren't limited to tracked files.
+func (c *GitCache) worktreeChanged(changed string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, repo := range c.repos {
+		if repo.tracked[changed] || repo.tracked[filepath.Dir(changed)] {
+			c.invalidate(repo)
+		}
+	}
+}
+
+// invalidate drops every entry belonging to repo. Callers hold c.mu.
+func (c *GitCache) invalidate(repo *watchedRepo) {
+	c.generation++
+	for dir, entry := range c.entries {
+		if entry.repo == repo {
+			delete(c.entries, dir)
+		}
+	}
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
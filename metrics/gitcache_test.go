@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/fswatch"
+	"github.com/DieGopherLT/cc-status-line/metrics/gittest"
+)
+
+// countingGit runs real git and counts the expensive diff calls
+type countingGit struct {
+	diffs atomic.Int64
+}
+
+func (g *countingGit) Run(dir string, args ...string) ([]byte, error) {
+	if len(args) > 0 && args[0] == "diff" {
+		g.diffs.Add(1)
+	}
+	return ExecGit{}.Run(dir, args...)
+}
+
+// newWatchedCache returns a cache that never expires by time, so only watches refresh it
+func newWatchedCache(t *testing.T) (*GitCache, *countingGit) {
+	t.Helper()
+
+	watcher, err := fswatch.New()
+	if err != nil {
+		t.Skipf("file watching unavailable: %v", err)
+	}
+	t.Cleanup(func() { watcher.Close() })
+
+	git := &countingGit{}
+	return NewGitCache(git, watcher, time.Hour), git
+}
+
+// eventually polls condition until it holds or a second has passed
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGitCacheWatchesTrackedFiles(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	repo.WriteFile("pkg/deep/file.go", "package deep\n")
+	repo.Commit("add nested file")
+	cache, git := newWatchedCache(t)
+
+	if got := cache.Get(repo.Dir); got.HasChanges {
+		t.Fatalf("Get() = %+v, want a clean tree", *got)
+	}
+	if cache.Get(repo.Dir); git.diffs.Load() != 1 {
+		t.Errorf("second Get() ran git diff again (%d calls)", git.diffs.Load())
+	}
+
+	repo.WriteFile("pkg/deep/file.go", "package deep\n\nvar x = 1\n")
+	eventually(t, "a nested edit to invalidate the cache", func() bool {
+		return cache.Get(repo.Dir).Additions == 2
+	})
+}
+
+func TestGitCacheCountsUntrackedFiles(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	cache, _ := newWatchedCache(t)
+	if got := cache.Get(repo.Dir); got.Untracked != 0 {
+		t.Fatalf("Untracked = %d, want 0", got.Untracked)
+	}
+
+	repo.MakeUntracked()
+	eventually(t, "an untracked file to invalidate the cache", func() bool {
+		return cache.Get(repo.Dir).Untracked == 1
+	})
+}
+
+func TestGitCacheWatchesMetadata(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	cache, _ := newWatchedCache(t)
+
+	repo.MakeStaged()
+	if got := cache.Get(repo.Dir); !got.HasChanges {
+		t.Fatalf("Get() = %+v, want staged changes", *got)
+	}
+
+	repo.Commit("commit staged change")
+	eventually(t, "a commit to invalidate the cache", func() bool {
+		return !cache.Get(repo.Dir).HasChanges
+	})
+
+	repo.Git("checkout", "--quiet", "-b", "feature/nested")
+	eventually(t, "a checkout to invalidate the cache", func() bool {
+		return cache.Get(repo.Dir).Branch == "feature/nested"
+	})
+
+	// The new branch lives in refs/heads/feature, which must be watched after the resync
+	repo.Git("update-ref", "refs/heads/feature/other", "HEAD")
+	repo.Git("symbolic-ref", "HEAD", "refs/heads/feature/other")
+	eventually(t, "a ref update to invalidate the cache", func() bool {
+		return cache.Get(repo.Dir).Branch == "feature/other"
+	})
+}
+
+func TestGitCacheFallsBackToTTL(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	cache, git := newWatchedCache(t)
+	cache.MaxWatches = 1
+	cache.ttl = 50 * time.Millisecond
+
+	cache.Get(repo.Dir)
+	repo.MakeDirty()
+	time.Sleep(100 * time.Millisecond)
+
+	if got := cache.Get(repo.Dir); !got.HasChanges || git.diffs.Load() != 2 {
+		t.Errorf("Get() = %+v after %d diffs, want changes from an expired entry", *got, git.diffs.Load())
+	}
+}
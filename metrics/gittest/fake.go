@@ -0,0 +1,73 @@
+// Package gittest provides git doubles for tests: a scripted in-memory provider
+// and helpers that build temporary repositories in specific states.
+package gittest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNotScripted is returned for git invocations the fake has no response for
+var ErrNotScripted = errors.New("gittest: command not scripted")
+
+// Response is the scripted result of one git invocation
+type Response struct {
+	Output string
+	Err    error
+}
+
+// Fake is an in-memory GitProvider that answers from a script keyed by the joined arguments,
+// e.g. "rev-parse --abbrev-ref HEAD". Unscripted commands fail with ErrNotScripted.
+type Fake struct {
+	mu        sync.Mutex
+	responses map[string]Response
+	calls     []string
+}
+
+// NewFake returns a fake with an empty script
+func NewFake() *Fake {
+	return &Fake{responses: make(map[string]Response)}
+}
+
+// On scripts a successful response
+func (f *Fake) On(args string, output string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[args] = Response{Output: output}
+	return f
+}
+
+// Fail scripts a failing response
+func (f *Fake) Fail(args string, err error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[args] = Response{Err: err}
+	return f
+}
+
+// Run implements metrics.GitProvider
+func (f *Fake) Run(dir string, args ...string) ([]byte, error) {
+	key := strings.Join(args, " ")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, key)
+
+	response, ok := f.responses[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: git %s", ErrNotScripted, key)
+	}
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	return []byte(response.Output), nil
+}
+
+// Calls returns the joined arguments of every invocation, in order
+func (f *Fake) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
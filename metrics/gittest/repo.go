@@ -0,0 +1,193 @@
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Repo is a temporary git repository owned by a test
+type Repo struct {
+	t   testing.TB
+	Dir string
+}
+
+// NewRepo creates a repository on branch main with one commit containing README.md.
+// The test is skipped when git is not installed.
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := &Repo{t: t, Dir: t.TempDir()}
+	repo.Git("init", "--quiet", "--initial-branch=main")
+	repo.WriteFile("README.md", "line 1\nline 2\nline 3\n")
+	repo.Commit("initial commit")
+
+	return repo
+}
+
+// Git runs a git command in the repository and returns its trimmed output, failing the test on error
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+
+	output, err := r.run(args...)
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return output
+}
+
+// GitMayFail runs a git command that is expected to fail in some states, such as a conflicting merge
+func (r *Repo) GitMayFail(args ...string) string {
+	r.t.Helper()
+
+	output, _ := r.run(args...)
+	return output
+}
+
+// run executes git with an isolated, deterministic identity and config
+func (r *Repo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=gittest",
+		"GIT_AUTHOR_EMAIL=gittest@example.com",
+		"GIT_COMMITTER_NAME=gittest",
+		"GIT_COMMITTER_EMAIL=gittest@example.com",
+		"GIT_EDITOR=true",
+	)
+
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// WriteFile writes a file relative to the repository root, creating parent directories
+func (r *Repo) WriteFile(name, content string) {
+	r.t.Helper()
+	r.WriteBytes(name, []byte(content))
+}
+
+// WriteBytes writes raw bytes, e.g. binary content, relative to the repository root
+func (r *Repo) WriteBytes(name string, content []byte) {
+	r.t.Helper()
+
+	path := filepath.Join(r.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		r.t.Fatalf("failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		r.t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// Commit stages everything and commits it
+func (r *Repo) Commit(message string) {
+	r.t.Helper()
+	r.Git("add", "--all")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", message)
+}
+
+// MakeDirty appends two lines to README.md without staging them
+func (r *Repo) MakeDirty() {
+	r.t.Helper()
+	r.WriteFile("README.md", "line 1\nline 2\nline 3\nline 4\nline 5\n")
+}
+
+// MakeStaged replaces a line of README.md and stages the change
+func (r *Repo) MakeStaged() {
+	r.t.Helper()
+	r.WriteFile("README.md", "line 1\nchanged\nline 3\n")
+	r.Git("add", "README.md")
+}
+
+// MakeUntracked adds a file git does not track
+func (r *Repo) MakeUntracked() {
+	r.t.Helper()
+	r.WriteFile("untracked.txt", "new\nfile\n")
+}
+
+// MakeBinaryChange commits a binary file and modifies it, so numstat reports "-" counts
+func (r *Repo) MakeBinaryChange() {
+	r.t.Helper()
+	r.WriteBytes("image.bin", []byte{0x00, 0x01, 0x02, 0xff})
+	r.Commit("add binary")
+	r.WriteBytes("image.bin", []byte{0x00, 0x03, 0x04, 0xfe, 0x00})
+}
+
+// MakeRename stages a rename of README.md
+func (r *Repo) MakeRename() {
+	r.t.Helper()
+	if err := os.MkdirAll(filepath.Join(r.Dir, "docs"), 0o755); err != nil {
+		r.t.Fatalf("failed to create docs directory: %v", err)
+	}
+	r.Git("mv", "README.md", "docs/README.md")
+}
+
+// MakeConflict leaves a merge of a diverging branch stopped on a conflict in README.md
+func (r *Repo) MakeConflict() {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "-b", "other")
+	r.WriteFile("README.md", "line 1\nother side\nline 3\n")
+	r.Commit("other side")
+
+	r.Git("checkout", "--quiet", "main")
+	r.WriteFile("README.md", "line 1\nmain side\nline 3\n")
+	r.Commit("main side")
+
+	r.GitMayFail("merge", "--quiet", "other")
+}
+
+// MakeDetached checks out the current commit without a branch
+func (r *Repo) MakeDetached() {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "--detach")
+}
+
+// MakeRebaseInProgress leaves a rebase stopped on a conflict, with HEAD detached
+func (r *Repo) MakeRebaseInProgress() {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "-b", "topic")
+	r.WriteFile("README.md", "line 1\ntopic side\nline 3\n")
+	r.Commit("topic side")
+
+	r.Git("checkout", "--quiet", "main")
+	r.WriteFile("README.md", "line 1\nmain side\nline 3\n")
+	r.Commit("main side")
+
+	r.Git("checkout", "--quiet", "topic")
+	r.GitMayFail("rebase", "main")
+}
+
+// Tag tags the current commit
+func (r *Repo) Tag(name string) {
+	r.t.Helper()
+	r.Git("tag", name)
+}
+
+// AddWorktree creates a linked worktree named name on a new branch of the same name
+func (r *Repo) AddWorktree(name string) *Repo {
+	r.t.Helper()
+
+	dir := filepath.Join(r.t.TempDir(), name)
+	r.Git("worktree", "add", "--quiet", "-b", name, dir)
+	return &Repo{t: r.t, Dir: dir}
+}
+
+// AddSubmodule creates a separate repository and commits it as a submodule at path,
+// returning the submodule's checkout inside this repository
+func (r *Repo) AddSubmodule(path string) *Repo {
+	r.t.Helper()
+
+	source := NewRepo(r.t)
+	// Local clones of file paths are disabled by default since git 2.38.1
+	r.Git("-c", "protocol.file.allow=always", "submodule", "add", "--quiet", source.Dir, path)
+	r.Commit("add submodule " + path)
+	return &Repo{t: r.t, Dir: filepath.Join(r.Dir, filepath.FromSlash(path))}
+}
@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InfraContext is the environment a command line tool would currently act on. It is read
+// from local config files and the environment only; nothing touches the network.
+type InfraContext struct {
+	Name   string // Context, profile or configuration name
+	Detail string // Namespace, region or project; empty when unset
+}
+
+// InfraDetector reads one tool's current context, returning nil when the tool is not configured
+type InfraDetector func(getenv func(string) string, home string) *InfraContext
+
+// InfraDetectors maps each infrastructure segment to its detector
+var InfraDetectors = map[string]InfraDetector{
+	"kube":   KubeContext,
+	"aws":    AWSProfile,
+	"gcloud": GcloudConfiguration,
+	"docker": DockerContext,
+}
+
+// KubeContext reads the current context and its namespace from the kubeconfig files in
+// $KUBECONFIG, or ~/.kube/config. Like kubectl, the first file to set a value wins.
+func KubeContext(getenv func(string) string, home string) *InfraContext {
+	paths := filepath.SplitList(getenv("KUBECONFIG"))
+	if len(paths) == 0 {
+		paths = []string{filepath.Join(home, ".kube", "config")}
+	}
+
+	var configs []kubeconfig
+	current := ""
+	for _, path := range paths {
+		config, err := readKubeconfig(path)
+		if err != nil {
+			continue
+		}
+		configs = append(configs, config)
+		if current == "" {
+			current = config.currentContext
+		}
+	}
+	if current == "" {
+		return nil
+	}
+
+	for _, config := range configs {
+		if namespace, ok := config.namespaces[current]; ok {
+			return &InfraContext{Name: current, Detail: namespace}
+		}
+	}
+	return &InfraContext{Name: current}
+}
+
+// kubeconfig holds the parts of a kubeconfig file the kube segment shows
+type kubeconfig struct {
+	currentContext string
+	namespaces     map[string]string // Namespace of each context, empty when it has none
+}
+
+// readKubeconfig reads current-context and the contexts list from a kubeconfig file.
+// It understands the block YAML kubectl writes rather than YAML in general.
+func readKubeconfig(path string) (kubeconfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return kubeconfig{}, err
+	}
+	defer file.Close()
+
+	config := kubeconfig{namespaces: make(map[string]string)}
+	section := ""
+	itemIndent := -1
+	var name, namespace string
+	flush := func() {
+		if name != "" {
+			config.namespaces[name] = namespace
+		}
+		name, namespace = "", ""
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		// A top-level key starts a new section
+		if indent == 0 && !strings.HasPrefix(trimmed, "-") {
+			flush()
+			key, value := yamlKeyValue(trimmed)
+			section = key
+			if key == "current-context" {
+				config.currentContext = value
+			}
+			continue
+		}
+		if section != "contexts" {
+			continue
+		}
+
+		// "- " starts a list item; its keys are indented past the dash
+		if rest, ok := strings.CutPrefix(trimmed, "- "); ok {
+			flush()
+			indent += 2
+			itemIndent = indent
+			trimmed = rest
+		}
+
+		switch key, value := yamlKeyValue(trimmed); {
+		case key == "name" && indent == itemIndent:
+			name = value
+		case key == "namespace" && indent > itemIndent:
+			namespace = value
+		}
+	}
+	flush()
+
+	return config, scanner.Err()
+}
+
+// yamlKeyValue splits a "key: value" line, unquoting the value and dropping trailing comments
+func yamlKeyValue(line string) (string, string) {
+	key, value, _ := strings.Cut(line, ":")
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return strings.TrimSpace(key), value[1 : len(value)-1]
+	}
+	if before, _, ok := strings.Cut(value, " #"); ok {
+		value = strings.TrimSpace(before)
+	}
+	return strings.TrimSpace(key), value
+}
+
+// AWSProfile reads the active profile from $AWS_PROFILE and its region from $AWS_REGION or
+// the AWS config file. Without a profile in the environment, the default profile is shown
+// when the config file defines one.
+func AWSProfile(getenv func(string) string, home string) *InfraContext {
+	path := getenv("AWS_CONFIG_FILE")
+	if path == "" {
+		path = filepath.Join(home, ".aws", "config")
+	}
+	sections, _ := readINI(path)
+
+	profile := firstNonEmpty(getenv("AWS_PROFILE"), getenv("AWS_DEFAULT_PROFILE"))
+	section := "profile " + profile
+	if profile == "" || profile == "default" {
+		if _, ok := sections["default"]; !ok && profile == "" {
+			return nil
+		}
+		profile, section = "default", "default"
+	}
+
+	region := firstNonEmpty(getenv("AWS_REGION"), getenv("AWS_DEFAULT_REGION"), sections[section]["region"])
+	return &InfraContext{Name: profile, Detail: region}
+}
+
+// GcloudConfiguration reads the active gcloud configuration and its project from
+// $CLOUDSDK_CONFIG, or ~/.config/gcloud
+func GcloudConfiguration(getenv func(string) string, home string) *InfraContext {
+	dir := getenv("CLOUDSDK_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(home, ".config", "gcloud")
+	}
+
+	name := getenv("CLOUDSDK_ACTIVE_CONFIG_NAME")
+	if name == "" {
+		data, err := os.ReadFile(filepath.Join(dir, "active_config"))
+		if err != nil {
+			return nil
+		}
+		name = strings.TrimSpace(string(data))
+	}
+	if name == "" {
+		return nil
+	}
+
+	sections, _ := readINI(filepath.Join(dir, "configurations", "config_"+name))
+	project := firstNonEmpty(getenv("CLOUDSDK_CORE_PROJECT"), sections["core"]["project"])
+	return &InfraContext{Name: name, Detail: project}
+}
+
+// DockerContext reads the Docker context from $DOCKER_CONTEXT, $DOCKER_HOST or the Docker
+// config file. The default context talks to the local daemon and is not shown.
+func DockerContext(getenv func(string) string, home string) *InfraContext {
+	if name := getenv("DOCKER_CONTEXT"); name != "" && name != "default" {
+		return &InfraContext{Name: name}
+	}
+	if host := getenv("DOCKER_HOST"); host != "" && !strings.HasPrefix(host, "unix://") && !strings.HasPrefix(host, "npipe://") {
+		return &InfraContext{Name: host}
+	}
+
+	dir := getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(home, ".docker")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil
+	}
+
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil || config.CurrentContext == "" || config.CurrentContext == "default" {
+		return nil
+	}
+	return &InfraContext{Name: config.CurrentContext}
+}
+
+// readINI reads the sections of an INI file as used by the AWS and gcloud CLIs
+func readINI(path string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if sections[name] == nil {
+				sections[name] = make(map[string]string)
+			}
+			current = sections[name]
+		case current != nil:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				current[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return sections, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
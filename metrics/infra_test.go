@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// kubectlConfig is a kubeconfig as written by kubectl
+const kubectlConfig = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://prod.example.com
+  name: prod
+contexts:
+- context:
+    cluster: prod
+    namespace: payments
+    user: admin
+  name: prod-eu
+- context:
+    cluster: dev
+    user: dev
+  name: "dev"
+current-context: prod-eu
+kind: Config
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func TestInfraDetectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		detect InfraDetector
+		files  map[string]string // Relative to the home directory
+		env    map[string]string
+		want   *InfraContext
+	}{
+		{name: "kube not configured", detect: KubeContext, want: nil},
+		{
+			name:   "kube context with namespace",
+			detect: KubeContext,
+			files:  map[string]string{".kube/config": kubectlConfig},
+			want:   &InfraContext{Name: "prod-eu", Detail: "payments"},
+		},
+		{
+			name:   "kube context without namespace",
+			detect: KubeContext,
+			files:  map[string]string{".kube/config": strings.Replace(kubectlConfig, "current-context: prod-eu", "current-context: dev", 1)},
+			want:   &InfraContext{Name: "dev"},
+		},
+		{
+			name:   "first kubeconfig setting current-context wins",
+			detect: KubeContext,
+			files: map[string]string{
+				".kube/dev":    "current-context: dev\n",
+				".kube/config": kubectlConfig,
+			},
+			env:  map[string]string{"KUBECONFIG": "{home}/.kube/dev:{home}/.kube/config"},
+			want: &InfraContext{Name: "dev"},
+		},
+		{name: "aws not configured", detect: AWSProfile, want: nil},
+		{
+			name:   "aws default profile",
+			detect: AWSProfile,
+			files:  map[string]string{".aws/config": "[default]\nregion = us-east-1\n"},
+			want:   &InfraContext{Name: "default", Detail: "us-east-1"},
+		},
+		{
+			name:   "aws profile from the environment",
+			detect: AWSProfile,
+			files:  map[string]string{".aws/config": "[default]\nregion = us-east-1\n\n[profile prod]\nregion = eu-west-1\n"},
+			env:    map[string]string{"AWS_PROFILE": "prod"},
+			want:   &InfraContext{Name: "prod", Detail: "eu-west-1"},
+		},
+		{
+			name:   "aws region from the environment",
+			detect: AWSProfile,
+			env:    map[string]string{"AWS_PROFILE": "staging", "AWS_REGION": "ap-south-1"},
+			want:   &InfraContext{Name: "staging", Detail: "ap-south-1"},
+		},
+		{name: "gcloud not configured", detect: GcloudConfiguration, want: nil},
+		{
+			name:   "gcloud active configuration",
+			detect: GcloudConfiguration,
+			files: map[string]string{
+				".config/gcloud/active_config":              "work\n",
+				".config/gcloud/configurations/config_work": "[core]\naccount = dev@example.com\nproject = billing-prod\n",
+			},
+			want: &InfraContext{Name: "work", Detail: "billing-prod"},
+		},
+		{name: "docker default context", detect: DockerContext, files: map[string]string{".docker/config.json": `{"currentContext": "default"}`}, want: nil},
+		{
+			name:   "docker context from config",
+			detect: DockerContext,
+			files:  map[string]string{".docker/config.json": `{"auths": {}, "currentContext": "remote-prod"}`},
+			want:   &InfraContext{Name: "remote-prod"},
+		},
+		{
+			name:   "docker host from the environment",
+			detect: DockerContext,
+			env:    map[string]string{"DOCKER_HOST": "tcp://build.example.com:2376"},
+			want:   &InfraContext{Name: "tcp://build.example.com:2376"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(home, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			vars := make(map[string]string)
+			for key, value := range tt.env {
+				vars[key] = strings.ReplaceAll(value, "{home}", home)
+			}
+
+			if got := tt.detect(env(vars), home); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
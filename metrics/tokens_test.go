@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/DieGopherLT/cc-status-line/parser"
+)
+
+func TestCalculateTokenMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		window *parser.ContextWindow
+		want   TokenMetrics
+	}{
+		{name: "nil window", window: nil, want: TokenMetrics{}},
+		{
+			name: "current usage",
+			window: &parser.ContextWindow{
+				TotalInputTokens:  99999,
+				ContextWindowSize: 200000,
+				CurrentUsage:      &parser.CurrentUsage{InputTokens: 30000, OutputTokens: 5000, CacheCreationInputTokens: 10000, CacheReadInputTokens: 10000},
+			},
+			want: TokenMetrics{ContextLength: 50000, ContextPercentage: 25, ContextWindowSize: 200000},
+		},
+		{
+			name:   "legacy totals",
+			window: &parser.ContextWindow{TotalInputTokens: 15000, TotalOutputTokens: 5000, ContextWindowSize: 200000},
+			want:   TokenMetrics{ContextLength: 20000, ContextPercentage: 10, ContextWindowSize: 200000},
+		},
+		{
+			name:   "zero window size",
+			window: &parser.ContextWindow{TotalInputTokens: 15000},
+			want:   TokenMetrics{ContextLength: 15000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateTokenMetrics(tt.window); *got != tt.want {
+				t.Errorf("CalculateTokenMetrics() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkCalculateTokenMetrics(b *testing.B) {
+	window := &parser.ContextWindow{
+		ContextWindowSize: 200000,
+		CurrentUsage:      &parser.CurrentUsage{InputTokens: 8500, CacheCreationInputTokens: 5000, CacheReadInputTokens: 2000},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		CalculateTokenMetrics(window)
+	}
+}
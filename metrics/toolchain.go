@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Toolchain is a language a project declares, with the version it asks for
+type Toolchain struct {
+	Language string // Lowercase name: go, rust, node, python, or an asdf plugin name
+	Version  string // Declared version or constraint, e.g. "1.22" or ">=3.11"; empty when unversioned
+	Source   string // File the version came from
+	Active   string // Active environment read from the environment: virtualenv name or Node version
+}
+
+// toolchainOrder fixes the display order of well-known languages; others follow in file order
+var toolchainOrder = []string{"go", "rust", "node", "python"}
+
+// asdfNames maps .tool-versions plugin names to language names
+var asdfNames = map[string]string{
+	"golang": "go",
+	"nodejs": "node",
+}
+
+// nvmVersionPattern finds the Node version in NVM_BIN, e.g. ~/.nvm/versions/node/v20.11.1/bin
+var nvmVersionPattern = regexp.MustCompile(`[/\\]node[/\\](v\d+[^/\\]*)`)
+
+// DetectToolchains inspects the manifests in dir and the environment seen through getenv.
+// Pinned versions (.tool-versions, .python-version, .nvmrc) take precedence over
+// manifest constraints. Unreadable or malformed files are skipped.
+func DetectToolchains(dir string, getenv func(string) string) []Toolchain {
+	detected := make(map[string]*Toolchain)
+	var order []string
+
+	declare := func(language, version, source string) {
+		toolchain, ok := detected[language]
+		if !ok {
+			toolchain = &Toolchain{Language: language}
+			detected[language] = toolchain
+			order = append(order, language)
+		}
+		// Later declarations are pins that override constraints, unless they have no version
+		if version != "" || toolchain.Source == "" {
+			toolchain.Version, toolchain.Source = version, source
+		}
+	}
+
+	if version, ok := goVersion(filepath.Join(dir, "go.mod")); ok {
+		declare("go", version, "go.mod")
+	}
+	if version, ok := rustVersion(filepath.Join(dir, "Cargo.toml")); ok {
+		declare("rust", version, "Cargo.toml")
+	}
+	if version, ok := nodeEngine(filepath.Join(dir, "package.json")); ok {
+		declare("node", version, "package.json")
+	}
+	if version, ok := requiresPython(filepath.Join(dir, "pyproject.toml")); ok {
+		declare("python", version, "pyproject.toml")
+	}
+	if version, ok := firstLine(filepath.Join(dir, ".python-version")); ok {
+		declare("python", version, ".python-version")
+	}
+	if version, ok := firstLine(filepath.Join(dir, ".nvmrc")); ok {
+		declare("node", version, ".nvmrc")
+	}
+	for _, pin := range toolVersions(filepath.Join(dir, ".tool-versions")) {
+		declare(pin[0], pin[1], ".tool-versions")
+	}
+
+	if venv := activeVirtualenv(getenv); venv != "" {
+		declare("python", "", "")
+		detected["python"].Active = venv
+	}
+	if node := activeNode(getenv); node != "" {
+		declare("node", "", "")
+		detected["node"].Active = node
+	}
+
+	var toolchains []Toolchain
+	for _, language := range toolchainOrder {
+		if toolchain, ok := detected[language]; ok {
+			toolchains = append(toolchains, *toolchain)
+		}
+	}
+	for _, language := range order {
+		if !slices.Contains(toolchainOrder, language) {
+			toolchains = append(toolchains, *detected[language])
+		}
+	}
+	return toolchains
+}
+
+// goVersion reads the toolchain directive of go.mod, falling back to the go directive
+func goVersion(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	version := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "toolchain":
+			return strings.TrimPrefix(fields[1], "go"), true
+		case "go":
+			version = fields[1]
+		}
+	}
+	return version, true
+}
+
+// rustVersion reads package.rust-version from Cargo.toml
+func rustVersion(path string) (string, bool) {
+	var manifest struct {
+		Package struct {
+			RustVersion any `toml:"rust-version"` // A table in workspace members: { workspace = true }
+		} `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(path, &manifest); err != nil {
+		return "", false
+	}
+	version, _ := manifest.Package.RustVersion.(string)
+	return version, true
+}
+
+// nodeEngine reads engines.node from package.json
+func nodeEngine(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	var manifest struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", false
+	}
+	return manifest.Engines.Node, true
+}
+
+// requiresPython reads project.requires-python from pyproject.toml, or the python
+// dependency of a Poetry project
+func requiresPython(path string) (string, bool) {
+	var manifest struct {
+		Project struct {
+			RequiresPython string `toml:"requires-python"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if _, err := toml.DecodeFile(path, &manifest); err != nil {
+		return "", false
+	}
+
+	if manifest.Project.RequiresPython != "" {
+		return manifest.Project.RequiresPython, true
+	}
+	if version, ok := manifest.Tool.Poetry.Dependencies["python"].(string); ok {
+		return version, true
+	}
+	return "", true
+}
+
+// firstLine returns the first non-empty, non-comment line of a version file
+func firstLine(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// toolVersions reads asdf/mise pins as [language, version] pairs in file order
+func toolVersions(path string) [][2]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var pins [][2]string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		language := fields[0]
+		if name, ok := asdfNames[language]; ok {
+			language = name
+		}
+		pins = append(pins, [2]string{language, fields[1]})
+	}
+	return pins
+}
+
+// activeVirtualenv names the active Python virtualenv or conda environment
+func activeVirtualenv(getenv func(string) string) string {
+	if venv := getenv("VIRTUAL_ENV"); venv != "" {
+		// Project-local environments are usually called .venv; the project name is more telling
+		if prompt := getenv("VIRTUAL_ENV_PROMPT"); prompt != "" {
+			return strings.Trim(strings.TrimSpace(prompt), "()")
+		}
+		return filepath.Base(venv)
+	}
+	if env := getenv("CONDA_DEFAULT_ENV"); env != "" && env != "base" {
+		return env
+	}
+	return ""
+}
+
+// activeNode reads the Node version selected by nvm, or set by Node container images
+func activeNode(getenv func(string) string) string {
+	if match := nvmVersionPattern.FindStringSubmatch(getenv("NVM_BIN")); match != nil {
+		return match[1]
+	}
+	if version := getenv("NODE_VERSION"); version != "" {
+		return "v" + strings.TrimPrefix(version, "v")
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// env returns a getenv over a fixed set of variables
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetectToolchains(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		want  []Toolchain
+	}{
+		{name: "nothing detected", want: nil},
+		{
+			name:  "go toolchain directive wins over go directive",
+			files: map[string]string{"go.mod": "module example.com/x\n\ngo 1.22 // minimum\n\ntoolchain go1.22.3\n"},
+			want:  []Toolchain{{Language: "go", Version: "1.22.3", Source: "go.mod"}},
+		},
+		{
+			name: "pins override manifest constraints",
+			files: map[string]string{
+				"package.json":    `{"name": "web", "engines": {"node": ">=18"}}`,
+				".nvmrc":          "20.11.1\n",
+				"pyproject.toml":  "[project]\nname = \"x\"\nrequires-python = \">=3.11\"\n",
+				".python-version": "# pinned\n3.12.1\n",
+			},
+			want: []Toolchain{
+				{Language: "node", Version: "20.11.1", Source: ".nvmrc"},
+				{Language: "python", Version: "3.12.1", Source: ".python-version"},
+			},
+		},
+		{
+			name: "tool-versions maps asdf names and keeps unknown tools",
+			files: map[string]string{
+				"Cargo.toml":     "[package]\nname = \"x\"\nrust-version.workspace = true\n",
+				".tool-versions": "ruby 3.3.0\ngolang 1.23.1 # build\nnodejs 22.2.0\n",
+			},
+			want: []Toolchain{
+				{Language: "go", Version: "1.23.1", Source: ".tool-versions"},
+				{Language: "rust", Source: "Cargo.toml"},
+				{Language: "node", Version: "22.2.0", Source: ".tool-versions"},
+				{Language: "ruby", Version: "3.3.0", Source: ".tool-versions"},
+			},
+		},
+		{
+			name:  "poetry python dependency",
+			files: map[string]string{"pyproject.toml": "[tool.poetry.dependencies]\npython = \"^3.10\"\n"},
+			want:  []Toolchain{{Language: "python", Version: "^3.10", Source: "pyproject.toml"}},
+		},
+		{
+			name:  "active environments",
+			files: map[string]string{"pyproject.toml": "[project]\nrequires-python = \">=3.11\"\n"},
+			env: map[string]string{
+				"VIRTUAL_ENV": "/home/dev/api/.venv", "VIRTUAL_ENV_PROMPT": "(api) ",
+				"NVM_BIN": "/home/dev/.nvm/versions/node/v20.11.1/bin",
+			},
+			want: []Toolchain{
+				{Language: "node", Active: "v20.11.1"},
+				{Language: "python", Version: ">=3.11", Source: "pyproject.toml", Active: "api"},
+			},
+		},
+		{
+			name:  "malformed manifests are skipped",
+			files: map[string]string{"package.json": "{", "Cargo.toml": "[package"},
+			env:   map[string]string{"CONDA_DEFAULT_ENV": "base"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			if got := DetectToolchains(dir, env(tt.env)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectToolchains() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// decodeDocument decodes the hook as a generic JSON tree, keeping numbers in their textual form
+func decodeDocument(data []byte) map[string]any {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil
+	}
+	return document
+}
+
+// unknownFields returns the parts of document that have no matching json tag in t, keeping their nesting
+func unknownFields(document map[string]any, t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	known := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known[name] = field.Type
+		}
+	}
+
+	extra := make(map[string]any)
+	for key, value := range document {
+		fieldType, ok := known[key]
+		if !ok {
+			extra[key] = value
+			continue
+		}
+
+		// Recurse into known objects to find unknown nested fields
+		nested, isObject := value.(map[string]any)
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if isObject && fieldType.Kind() == reflect.Struct {
+			if sub := unknownFields(nested, fieldType); len(sub) > 0 {
+				extra[key] = sub
+			}
+		}
+	}
+
+	return extra
+}
+
+// Lookup resolves a dotted path such as "workspace.foo" against the raw hook input.
+// Known and unknown fields are both reachable; array elements are addressed by index ("items.0").
+func (h *StatusHook) Lookup(path string) (any, bool) {
+	var current any = h.document
+	if path == "" || h.document == nil {
+		return nil, false
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			var index int
+			if _, err := fmt.Sscanf(part, "%d", &index); err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the Claude Code version that produced a hook payload
+type SchemaVersion struct {
+	Major int
+	Minor int
+	Patch int
+	Known bool // False when the version string could not be parsed
+}
+
+// Capability names an optional part of the hook payload that only some Claude Code versions send
+type Capability string
+
+const (
+	CapContextWindow Capability = "context_window"
+	CapOutputStyle   Capability = "output_style"
+	CapCost          Capability = "cost"
+	CapLineCounts    Capability = "cost.total_lines_added"
+)
+
+// ParseSchemaVersion parses versions like "2.0.28", "v1.0.80" or "2.1.0-beta.1".
+// Missing minor or patch components are treated as zero.
+func ParseSchemaVersion(version string) SchemaVersion {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "-") // Drop pre-release suffixes
+	version, _, _ = strings.Cut(version, "+") // Drop build metadata
+
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return SchemaVersion{}
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return SchemaVersion{}
+		}
+		numbers[i] = n
+	}
+
+	return SchemaVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Known: true}
+}
+
+func (v SchemaVersion) String() string {
+	if !v.Known {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Schema returns the parsed Claude Code version of the hook
+func (h *StatusHook) Schema() SchemaVersion {
+	return ParseSchemaVersion(h.Version)
+}
+
+// Supports reports whether the payload carried a capability, so callers can tell
+// "field absent in this Claude Code version" apart from "field present but zero"
+func (h *StatusHook) Supports(capability Capability) bool {
+	value, ok := h.Lookup(string(capability))
+	return ok && value != nil
+}
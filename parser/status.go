@@ -2,8 +2,26 @@ package parser
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
+)
+
+// ParseMode controls how missing or malformed hook fields are handled
+type ParseMode string
+
+const (
+	// ParseLenient repairs problems with defaults so the status line keeps rendering after schema changes
+	ParseLenient ParseMode = "lenient"
+	// ParseStrict rejects any input with problems, for testing against fixtures
+	ParseStrict ParseMode = "strict"
+)
+
+// Defaults used by lenient parsing when required fields are missing
+const (
+	DefaultModelName = "Claude"
+	DefaultVersion   = "?"
 )
 
 // StatusHook represents the JSON structure received from Claude Code's Status hook
@@ -18,6 +36,12 @@ type StatusHook struct {
 	OutputStyle    Output         `json:"output_style"`
 	Cost           Cost           `json:"cost"`
 	ContextWindow  *ContextWindow `json:"context_window"`
+
+	// Extra holds fields this parser doesn't know yet, nested as in the input,
+	// so newly added Claude Code data is usable before a release adds a struct field
+	Extra map[string]any `json:"-"`
+
+	document map[string]any // Raw input tree backing Lookup
 }
 
 // Model contains information about the Claude model being used
@@ -62,22 +86,99 @@ type ContextWindow struct {
 	CurrentUsage      *CurrentUsage `json:"current_usage"`
 }
 
-// ParseStatusHook reads and parses the status hook JSON from an io.Reader
+// ParseStatusHook reads and parses the status hook JSON from an io.Reader. Malformed JSON and
+// a missing model.display_name or version are errors; missing workspace directories are
+// filled in from cwd, as Decode does.
 func ParseStatusHook(reader io.Reader) (*StatusHook, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status hook JSON: %w", err)
+	}
+
+	hook, problems, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("failed to parse status hook JSON: %s", problems[0])
+	}
+	if problems := hook.repairRequired(); len(problems) > 0 {
+		return nil, errors.New(problems[0])
+	}
+	hook.repairWorkspace()
+
+	return hook, nil
+}
+
+// Decode parses status hook JSON and returns every validation problem found.
+// In lenient mode problems are repaired with defaults and only unreadable JSON is an error;
+// in strict mode the first problem is returned as the error.
+func Decode(data []byte, mode ParseMode) (*StatusHook, []string, error) {
+	hook, problems, err := decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems = append(problems, hook.repairRequired()...)
+	problems = append(problems, hook.repairWorkspace()...)
+
+	if mode == ParseStrict && len(problems) > 0 {
+		return nil, problems, errors.New(problems[0])
+	}
+
+	return hook, problems, nil
+}
+
+// decode unmarshals status hook JSON. A field of the wrong type is left zeroed and reported
+// as a problem; only unreadable JSON is an error.
+func decode(data []byte) (*StatusHook, []string, error) {
 	var hook StatusHook
-	decoder := json.NewDecoder(reader)
+	var problems []string
 
-	if err := decoder.Decode(&hook); err != nil {
-		return nil, fmt.Errorf("failed to parse status hook JSON: %w", err)
+	if err := json.Unmarshal(data, &hook); err != nil {
+		// A type mismatch leaves the offending field zeroed but decodes everything else
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, nil, fmt.Errorf("failed to parse status hook JSON: %w", err)
+		}
+		problems = append(problems, fmt.Sprintf("%s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value))
 	}
 
-	// Validate required fields
-	if hook.Model.DisplayName == "" {
-		return nil, fmt.Errorf("model.display_name is required")
+	// Keep the raw tree so new upstream fields remain reachable
+	hook.document = decodeDocument(data)
+	hook.Extra = unknownFields(hook.document, reflect.TypeOf(hook))
+
+	return &hook, problems, nil
+}
+
+// repairRequired fills in the fields every status line needs with defaults and reports the
+// missing ones
+func (h *StatusHook) repairRequired() []string {
+	var problems []string
+	if h.Model.DisplayName == "" {
+		problems = append(problems, "model.display_name is required")
+		h.Model.DisplayName = DefaultModelName
+		if h.Model.ID != "" {
+			h.Model.DisplayName = h.Model.ID
+		}
 	}
-	if hook.Version == "" {
-		return nil, fmt.Errorf("version is required")
+	if h.Version == "" {
+		problems = append(problems, "version is required")
+		h.Version = DefaultVersion
 	}
+	return problems
+}
 
-	return &hook, nil
+// repairWorkspace fills in missing workspace directories and reports them. Git and path
+// segments need a directory; the top-level cwd carries the same information.
+func (h *StatusHook) repairWorkspace() []string {
+	var problems []string
+	if h.Workspace.CurrentDir == "" {
+		problems = append(problems, "workspace.current_dir is missing")
+		h.Workspace.CurrentDir = h.CWD
+	}
+	if h.Workspace.ProjectDir == "" {
+		problems = append(problems, "workspace.project_dir is missing")
+		h.Workspace.ProjectDir = h.Workspace.CurrentDir
+	}
+	return problems
 }
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeLenientDefaults(t *testing.T) {
+	hook, problems, err := Decode([]byte(`{"model":{"id":"claude-opus-4-1"},"cwd":"/work","cost":{"total_cost_usd":"free"}}`), ParseLenient)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if hook.Model.DisplayName != "claude-opus-4-1" {
+		t.Errorf("DisplayName = %q, want model ID fallback", hook.Model.DisplayName)
+	}
+	if hook.Version != DefaultVersion {
+		t.Errorf("Version = %q, want %q", hook.Version, DefaultVersion)
+	}
+	if hook.Workspace.CurrentDir != "/work" || hook.Workspace.ProjectDir != "/work" {
+		t.Errorf("Workspace = %+v, want both directories from cwd", hook.Workspace)
+	}
+	if len(problems) != 5 {
+		t.Errorf("problems = %q, want 5 (type mismatch, display name, version, two directories)", problems)
+	}
+}
+
+func TestDecodeStrictRejectsProblems(t *testing.T) {
+	if _, _, err := Decode([]byte(`{"model":{"display_name":"Opus"}}`), ParseStrict); err == nil {
+		t.Error("Decode() accepted input without a version in strict mode")
+	}
+}
+
+func TestParseStatusHook(t *testing.T) {
+	// Only the model name and version are required; workspace gaps are filled from cwd
+	hook, err := ParseStatusHook(strings.NewReader(`{"model":{"display_name":"Opus"},"version":"2.0.0","cwd":"/work"}`))
+	if err != nil {
+		t.Fatalf("ParseStatusHook() error = %v", err)
+	}
+	if hook.Workspace.CurrentDir != "/work" || hook.Workspace.ProjectDir != "/work" {
+		t.Errorf("Workspace = %+v, want both directories from cwd", hook.Workspace)
+	}
+
+	for input, want := range map[string]string{
+		`{"version":"2.0.0"}`:                           "model.display_name is required",
+		`{"model":{"display_name":"Opus"}}`:             "version is required",
+		`{"model":{"display_name":"Opus"},"version":2}`: "failed to parse status hook JSON",
+		`{"model":`: "failed to parse status hook JSON",
+	} {
+		if _, err := ParseStatusHook(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseStatusHook(%s) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestDecodeUnknownFields(t *testing.T) {
+	hook, _, err := Decode([]byte(`{"version":"2.1.3","model":{"display_name":"Opus","tier":"max"},"workspace":{"current_dir":"/a","project_dir":"/a","foo":{"bar":[1,"x"]}},"exceeds_200k_tokens":true}`), ParseStrict)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if _, ok := hook.Extra["exceeds_200k_tokens"]; !ok {
+		t.Errorf("Extra = %v, missing top-level unknown field", hook.Extra)
+	}
+	if _, ok := hook.Extra["version"]; ok {
+		t.Errorf("Extra = %v, contains known field", hook.Extra)
+	}
+
+	lookups := map[string]string{
+		"model.tier":          "max",
+		"workspace.foo.bar.1": "x",
+		"version":             "2.1.3",
+	}
+	for path, want := range lookups {
+		if got, ok := hook.Lookup(path); !ok || got != want {
+			t.Errorf("Lookup(%q) = %v, %t, want %q", path, got, ok, want)
+		}
+	}
+	bar, ok := hook.Lookup("workspace.foo.bar")
+	if items, isArray := bar.([]any); !ok || !isArray || len(items) != 2 {
+		t.Errorf("Lookup(workspace.foo.bar) = %v, %t, want the array", bar, ok)
+	}
+	if got, ok := hook.Lookup("missing.path"); ok {
+		t.Errorf("Lookup(missing.path) = %v, want no value", got)
+	}
+
+	if schema := hook.Schema(); schema.String() != "2.1.3" {
+		t.Errorf("Schema() = %v, want 2.1.3", schema)
+	}
+	if hook.Supports(CapContextWindow) {
+		t.Error("Supports(context_window) = true for a payload without it")
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	// Seed with the rendering fixtures and the sample payload
+	seeds, _ := filepath.Glob(filepath.Join("..", "display", "testdata", "hooks", "*.json"))
+	seeds = append(seeds, filepath.Join("..", "status-line.json"))
+	for _, path := range seeds {
+		if data, err := os.ReadFile(path); err == nil {
+			f.Add(data, "model.display_name")
+		}
+	}
+	f.Add([]byte(`{}`), "")
+	f.Add([]byte(`{"model":null,"context_window":{"current_usage":null}}`), "context_window.current_usage")
+	f.Add([]byte(`{"workspace":[1,2,3]}`), "workspace.0")
+	f.Add([]byte(`[]`), "0")
+
+	f.Fuzz(func(t *testing.T, data []byte, path string) {
+		for _, mode := range []ParseMode{ParseLenient, ParseStrict} {
+			hook, problems, err := Decode(data, mode)
+			if err != nil {
+				continue
+			}
+
+			if hook == nil {
+				t.Fatalf("%s: nil hook without error", mode)
+			}
+			if hook.Model.DisplayName == "" || hook.Version == "" {
+				t.Errorf("%s: required fields left empty: %+v", mode, hook.Model)
+			}
+			if mode == ParseStrict && len(problems) > 0 {
+				t.Errorf("strict mode accepted input with problems %q", problems)
+			}
+
+			// Dotted lookups must never panic on arbitrary paths
+			hook.Lookup(path)
+			hook.Schema()
+		}
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join("..", "status-line.json"))
+	if err != nil {
+		b.Fatalf("failed to read sample input: %v", err)
+	}
+
+	for _, mode := range []ParseMode{ParseLenient, ParseStrict} {
+		b.Run(string(mode), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, _, err := Decode(data, mode); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+// runPreview renders every style (and optionally every glyph set) against a status hook file
+func runPreview(args []string) int {
+	cfg := loadConfig()
+
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	style := fs.String("style", "", "Preview a single style instead of all of them")
+	glyphs := fs.String("glyphs", cfg.Glyphs, "Glyph set: auto, unicode, nerdfont, ascii or all")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	styleNames := make([]string, len(display.Styles))
+	for i, info := range display.Styles {
+		styleNames[i] = info.Name
+	}
+	if *style != "" && !display.IsStyle(*style) {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown style %q (expected %s)\n", *style, strings.Join(styleNames, ", "))
+		return 2
+	}
+	if *glyphs != "all" && !isGlyphSetName(*glyphs) {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown glyph set %q (expected auto, unicode, nerdfont, ascii or all)\n", *glyphs)
+		return 2
+	}
+
+	inputFile := "status-line.json"
+	if fs.NArg() > 0 {
+		inputFile = fs.Arg(0)
+	}
+
+	input, err := os.ReadFile(inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	styles := []string{*style}
+	if *style == "" {
+		styles = styleNames
+	}
+
+	glyphSets := []*formatters.GlyphSet{formatters.ResolveGlyphSet(*glyphs)}
+	if *glyphs == "all" {
+		glyphSets = glyphSets[:0]
+		for _, level := range formatters.GlyphLevels {
+			glyphSets = append(glyphSets, formatters.GlyphSetFor(level))
+		}
+	}
+
+	// Render as the status line would, with the config of the input's project
+	opts, err := parseRenderFlags(nil, withProjectConfig(cfg, input, os.Stderr), os.Getenv, os.Stderr)
+	if err != nil {
+		return 2
+	}
+	opts.Stderr = os.Stderr
+	// A preview isn't racing a refresh, so it waits for the session logs to be read
+	opts.UsageCache = sessionlog.NewCache(sessionlog.DefaultCachePath())
+	opts.UsageCache.Deadline = time.Minute
+
+	fmt.Printf("Previewing status line styles with: %s\n\n", inputFile)
+
+	for _, glyphSet := range glyphSets {
+		for _, name := range styles {
+			fmt.Printf("=== %s (%s) ===\n", name, glyphSet.Level)
+
+			opts.Style, opts.Glyphs = name, glyphSet
+			statusLine, err := renderStatusLine(bytes.NewReader(input), opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+
+			fmt.Println(statusLine)
+			fmt.Println()
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/display/formatters"
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+// reportFormats lists the output formats of the report command
+var reportFormats = []string{"table", "csv", "json"}
+
+// runReport sums cost, tokens and session time across the sessions in Claude Code's logs
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	by := fs.String("by", string(sessionlog.ByDay), "Group by day, week, project or model")
+	format := fs.String("format", "table", "Output format: table, csv or json")
+	since := fs.String("since", "", "Only count sessions from this date on (YYYY-MM-DD)")
+	dir := fs.String("dir", sessionlog.ProjectsDir(os.Getenv, homeDir(os.Getenv)), "Claude Code project log directory")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	grouping := sessionlog.Grouping(*by)
+	if !slices.Contains(sessionlog.Groupings, grouping) {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown grouping %q (expected day, week, project or model)\n", *by)
+		return 2
+	}
+	if !slices.Contains(reportFormats, *format) {
+		fmt.Fprintf(os.Stderr, "cc-status-line: unknown format %q (expected table, csv or json)\n", *format)
+		return 2
+	}
+	var start time.Time
+	if *since != "" {
+		var err error
+		if start, err = time.ParseInLocation(time.DateOnly, *since, time.Local); err != nil {
+			fmt.Fprintf(os.Stderr, "cc-status-line: invalid --since date %q (expected YYYY-MM-DD)\n", *since)
+			return 2
+		}
+	}
+
+	entries, err := sessionlog.Read(*dir, start)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 1
+	}
+
+	rows := sessionlog.Aggregate(entries, grouping, time.Local)
+	total := sessionlog.Sum("total", entries)
+	if err := writeReport(os.Stdout, grouping, *format, rows, total); err != nil {
+		fmt.Fprintf(os.Stderr, "cc-status-line: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeReport prints the report rows in format. Tables are for reading and end with a
+// total; CSV and JSON keep exact numbers for spreadsheets and scripts.
+func writeReport(w io.Writer, by sessionlog.Grouping, format string, rows []sessionlog.Totals, total sessionlog.Totals) error {
+	switch format {
+	case "csv":
+		return writeReportCSV(w, by, rows)
+	case "json":
+		return writeReportJSON(w, by, rows, total)
+	default:
+		return writeReportTable(w, by, rows, total)
+	}
+}
+
+func writeReportTable(w io.Writer, by sessionlog.Grouping, rows []sessionlog.Totals, total sessionlog.Totals) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No sessions found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tSESSIONS\tINPUT\tOUTPUT\tCACHE WRITE\tCACHE READ\tCOST\tTIME\n", strings.ToUpper(string(by)))
+	for _, row := range append(slices.Clip(rows), total) {
+		key := row.Key
+		if key == "" {
+			key = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t$%.2f\t%s\n",
+			key,
+			row.Sessions,
+			formatters.HumanizeTokens(row.Tokens.Input),
+			formatters.HumanizeTokens(row.Tokens.Output),
+			formatters.HumanizeTokens(row.Tokens.CacheCreation),
+			formatters.HumanizeTokens(row.Tokens.CacheRead),
+			row.CostUSD,
+			formatters.HumanizeDuration(row.Duration))
+	}
+	return tw.Flush()
+}
+
+func writeReportCSV(w io.Writer, by sessionlog.Grouping, rows []sessionlog.Totals) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{string(by), "sessions", "requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "cost_usd", "duration_seconds"})
+	for _, row := range rows {
+		_ = cw.Write([]string{
+			row.Key,
+			strconv.Itoa(row.Sessions),
+			strconv.Itoa(row.Requests),
+			strconv.Itoa(row.Tokens.Input),
+			strconv.Itoa(row.Tokens.Output),
+			strconv.Itoa(row.Tokens.CacheCreation),
+			strconv.Itoa(row.Tokens.CacheRead),
+			strconv.FormatFloat(row.CostUSD, 'f', 4, 64),
+			strconv.FormatInt(int64(row.Duration.Seconds()), 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// reportRow is a report row as JSON
+type reportRow struct {
+	Key             string            `json:"key"`
+	Sessions        int               `json:"sessions"`
+	Requests        int               `json:"requests"`
+	Tokens          sessionlog.Tokens `json:"tokens"`
+	CostUSD         float64           `json:"cost_usd"`
+	DurationSeconds int64             `json:"duration_seconds"`
+}
+
+func writeReportJSON(w io.Writer, by sessionlog.Grouping, rows []sessionlog.Totals, total sessionlog.Totals) error {
+	toJSON := func(row sessionlog.Totals) reportRow {
+		return reportRow{
+			Key:             row.Key,
+			Sessions:        row.Sessions,
+			Requests:        row.Requests,
+			Tokens:          row.Tokens,
+			CostUSD:         math.Round(row.CostUSD*1e4) / 1e4,
+			DurationSeconds: int64(row.Duration.Seconds()),
+		}
+	}
+
+	report := struct {
+		By    sessionlog.Grouping `json:"by"`
+		Rows  []reportRow         `json:"rows"`
+		Total reportRow           `json:"total"`
+	}{By: by, Rows: make([]reportRow, 0, len(rows)), Total: toJSON(total)}
+	for _, row := range rows {
+		report.Rows = append(report.Rows, toJSON(row))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+// reportRows are two projects, one logged without a working directory
+var reportRows = []sessionlog.Totals{
+	{
+		Key: "/work/api", Sessions: 2, Requests: 14,
+		Tokens:   sessionlog.Tokens{Input: 1500, Output: 42_000, CacheCreation: 120_000, CacheRead: 2_300_000},
+		CostUSD:  12.345678,
+		Duration: 90 * time.Minute,
+	},
+	{
+		Key: "", Sessions: 1, Requests: 3,
+		Tokens:   sessionlog.Tokens{Input: 10, Output: 200},
+		CostUSD:  0.00004,
+		Duration: 45 * time.Second,
+	},
+}
+
+var reportTotal = sessionlog.Totals{
+	Key: "total", Sessions: 3, Requests: 17,
+	Tokens:   sessionlog.Tokens{Input: 1510, Output: 42_200, CacheCreation: 120_000, CacheRead: 2_300_000},
+	CostUSD:  12.345718,
+	Duration: 90*time.Minute + 45*time.Second,
+}
+
+func TestWriteReportTable(t *testing.T) {
+	var out strings.Builder
+	if err := writeReport(&out, sessionlog.ByProject, "table", reportRows, reportTotal); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("table has %d lines, want header, 2 rows and total:\n%s", len(lines), out.String())
+	}
+
+	want := [][]string{
+		{"PROJECT", "SESSIONS", "INPUT", "OUTPUT", "CACHE", "WRITE", "CACHE", "READ", "COST", "TIME"},
+		{"/work/api", "2", "1.5k", "42.0k", "120.0k", "2.3M", "$12.35", "1h30m"},
+		{"-", "1", "10", "200", "0", "0", "$0.00", "45s"},
+		{"total", "3", "1.5k", "42.2k", "120.0k", "2.3M", "$12.35", "1h30m"},
+	}
+	for i, line := range lines {
+		if got := strings.Fields(line); strings.Join(got, " ") != strings.Join(want[i], " ") {
+			t.Errorf("line %d = %q, want fields %q", i, line, want[i])
+		}
+	}
+
+	out.Reset()
+	if err := writeReport(&out, sessionlog.ByDay, "table", nil, sessionlog.Totals{Key: "total"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No sessions found\n" {
+		t.Errorf("empty table = %q, want a notice", out.String())
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	var out strings.Builder
+	if err := writeReport(&out, sessionlog.ByProject, "csv", reportRows, reportTotal); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exact numbers and no total row, so spreadsheets can sum the rows themselves
+	want := "project,sessions,requests,input_tokens,output_tokens,cache_creation_tokens,cache_read_tokens,cost_usd,duration_seconds\n" +
+		"/work/api,2,14,1500,42000,120000,2300000,12.3457,5400\n" +
+		",1,3,10,200,0,0,0.0000,45\n"
+	if out.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	var out strings.Builder
+	if err := writeReport(&out, sessionlog.ByProject, "json", reportRows, reportTotal); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		By    string      `json:"by"`
+		Rows  []reportRow `json:"rows"`
+		Total reportRow   `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	if report.By != "project" || len(report.Rows) != 2 {
+		t.Fatalf("report = %+v, want 2 project rows", report)
+	}
+	first := reportRow{Key: "/work/api", Sessions: 2, Requests: 14, Tokens: reportRows[0].Tokens, CostUSD: 12.3457, DurationSeconds: 5400}
+	if report.Rows[0] != first {
+		t.Errorf("rows[0] = %+v, want %+v", report.Rows[0], first)
+	}
+	if report.Rows[1].Key != "" || report.Rows[1].CostUSD != 0 {
+		t.Errorf("rows[1] = %+v, want an empty key and the cost rounded to 0", report.Rows[1])
+	}
+	if report.Total.Key != "total" || report.Total.CostUSD != 12.3457 || report.Total.DurationSeconds != 5445 {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	// No sessions still gives a rows array, not null
+	out.Reset()
+	if err := writeReport(&out, sessionlog.ByDay, "json", nil, sessionlog.Totals{Key: "total"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"rows": []`) {
+		t.Errorf("empty json = %s, want an empty rows array", out.String())
+	}
+}
@@ -0,0 +1,268 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies the tokens of a rule
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // Keyword, path or segment name
+	tokenNumber                  // Numeric literal
+	tokenString                  // Quoted string literal, unquoted
+	tokenSymbol                  // Operator or comma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// symbols lists the operators, longest first so ">=" is not read as ">"
+var symbols = []string{">=", "<=", "==", "!=", ">", "<", "=", ","}
+
+// tokenize splits a rule into tokens
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != src[i] {
+				if src[end] == '\\' && src[i] == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			text := src[i+1 : end]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(src[i : end+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+				}
+				text = unquoted
+			}
+			tokens = append(tokens, token{tokenString, text})
+			i = end + 1
+		case unicode.IsDigit(c) || c == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1])):
+			end := i + 1
+			for end < len(src) && (unicode.IsDigit(rune(src[end])) || src[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{tokenNumber, src[i:end]})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i + 1
+			for end < len(src) && isWordByte(src[end]) {
+				end++
+			}
+			tokens = append(tokens, token{tokenWord, src[i:end]})
+			i = end
+		default:
+			symbol := ""
+			for _, candidate := range symbols {
+				if strings.HasPrefix(src[i:], candidate) {
+					symbol = candidate
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{tokenSymbol, symbol})
+			i += len(symbol)
+		}
+	}
+	return tokens, nil
+}
+
+// isWordByte reports whether b continues a word; paths use dots and segment names dashes
+func isWordByte(b byte) bool {
+	return b == '_' || b == '.' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// parser is a recursive descent parser over the tokens of one rule
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the given word or symbol
+func (p *parser) accept(text string) bool {
+	if next, ok := p.peek(); ok && next.kind != tokenString && next.kind != tokenNumber && next.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+// word consumes a word token, which is not one of the keywords
+func (p *parser) word(what string) (string, error) {
+	next, ok := p.peek()
+	if !ok || next.kind != tokenWord || keywords[next.text] {
+		return "", p.errorf("expected %s", what)
+	}
+	p.pos++
+	return next.text, nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	found := "end of rule"
+	if next, ok := p.peek(); ok {
+		found = strconv.Quote(next.text)
+	}
+	return fmt.Errorf(format+", found %s", append(args, found)...)
+}
+
+var keywords = map[string]bool{
+	"when": true, "then": true, "and": true, "or": true, "not": true,
+	"matches": true, "show": true, "hide": true, "true": true, "false": true,
+}
+
+// parseOr parses conditions joined by "or"; "and" binds tighter
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = anyOf{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = allOf{left, right}
+	}
+	return left, nil
+}
+
+// parseComparison parses "[not] path [op value]"
+func (p *parser) parseComparison() (condition, error) {
+	if p.accept("not") {
+		inner, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		return negation{inner}, nil
+	}
+
+	path, err := p.word("a value path")
+	if err != nil {
+		return nil, err
+	}
+
+	next, ok := p.peek()
+	if !ok || next.kind != tokenSymbol && next.text != "matches" || next.text == "," || next.text == "=" {
+		return truthy{path}, nil
+	}
+	p.pos++
+	op := next.text
+
+	value, ok := p.peek()
+	if !ok || value.kind == tokenSymbol || value.kind == tokenWord && value.text != "true" && value.text != "false" {
+		return nil, p.errorf("expected a number, string, true or false after %q", op)
+	}
+	p.pos++
+
+	if op == "matches" {
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("matches needs a quoted pattern, found %q", value.text)
+		}
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", value.text, err)
+		}
+		return match{path, re}, nil
+	}
+
+	var literal any = value.text
+	switch {
+	case value.kind == tokenNumber:
+		number, err := strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value.text)
+		}
+		literal = number
+	case value.kind == tokenWord:
+		literal = value.text == "true"
+	}
+	return comparison{path, op, literal}, nil
+}
+
+// parseAction parses "show target", "hide target" or "target.property = value"
+func (p *parser) parseAction() (Action, error) {
+	for _, kind := range []ActionKind{ActionShow, ActionHide} {
+		if p.accept(string(kind)) {
+			target, err := p.word("a segment name")
+			return Action{Kind: kind, Target: target}, err
+		}
+	}
+
+	path, err := p.word(`"show", "hide" or a segment property`)
+	if err != nil {
+		return Action{}, err
+	}
+	target, property, ok := cutLast(path, ".")
+	kind := ActionKind(property)
+	if !ok || kind != ActionStyle && kind != ActionIcon {
+		return Action{}, fmt.Errorf("unknown property %q; use <segment>.style or <segment>.icon", path)
+	}
+	if err := p.expect("="); err != nil {
+		return Action{}, err
+	}
+
+	value, ok := p.peek()
+	if !ok || value.kind != tokenString {
+		return Action{}, p.errorf("expected a quoted %s", property)
+	}
+	p.pos++
+	return Action{Kind: kind, Target: target, Value: value.text}, nil
+}
+
+// cutLast splits s around the last sep
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
@@ -0,0 +1,313 @@
+// Package rules implements the conditional styling rules of the config file, e.g.
+//
+//	when ctx.pct > 80 then ctx.style = "bold red blink"
+//	when git.branch matches "^(main|master)$" then git.style = "reverse"
+//	when cost.total > 5 then show cost
+//
+// Conditions compare values from a snapshot of the status line data with numbers,
+// strings or booleans, joined with "and", "or" and "not". Actions restyle a segment,
+// replace its icon, or show and hide it.
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ActionKind is what an action changes
+type ActionKind string
+
+const (
+	ActionStyle ActionKind = "style" // Replace the segment's colors and attributes
+	ActionIcon  ActionKind = "icon"  // Replace or add the segment's icon
+	ActionShow  ActionKind = "show"  // Show a segment that is hidden unless a rule shows it
+	ActionHide  ActionKind = "hide"
+)
+
+// Action is one change a matching rule makes to a segment
+type Action struct {
+	Kind   ActionKind
+	Target string // Segment name, or the prefix shared by several, e.g. "git"
+	Value  string // Style or icon; empty for show and hide
+}
+
+// Rule is a parsed "when <condition> then <actions>" rule
+type Rule struct {
+	Source  string
+	when    condition
+	Actions []Action
+}
+
+// Lookup resolves a value path such as "ctx.pct" to its current value
+type Lookup func(path string) (any, bool)
+
+// Parse parses one rule
+func Parse(src string) (*Rule, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", src, err)
+	}
+
+	p := &parser{tokens: tokens}
+	rule, err := p.parseRule()
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", src, err)
+	}
+	rule.Source = src
+	return rule, nil
+}
+
+func (p *parser) parseRule() (*Rule, error) {
+	if err := p.expect("when"); err != nil {
+		return nil, err
+	}
+	when, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+
+	rule := &Rule{when: when}
+	for {
+		action, err := p.parseAction()
+		if err != nil {
+			return nil, err
+		}
+		rule.Actions = append(rule.Actions, action)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if _, ok := p.peek(); ok {
+		return nil, p.errorf("expected \",\" or end of rule")
+	}
+	return rule, nil
+}
+
+// ParseAll parses every rule, returning the valid ones and an error per invalid one
+func ParseAll(sources []string) ([]*Rule, []error) {
+	var parsed []*Rule
+	var errs []error
+	for _, src := range sources {
+		rule, err := Parse(src)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, errs
+}
+
+// Effect is the combined outcome of the rules for one target
+type Effect struct {
+	Style string // Style of the last matching rule setting one
+	Icon  string
+
+	Hide bool // A matching rule hides the target
+	Show bool // A matching rule shows the target
+
+	// Conditional is set when some rule can show the target, which hides it until one does
+	Conditional bool
+}
+
+// Visible reports whether the target is shown
+func (e Effect) Visible() bool {
+	return !e.Hide && (!e.Conditional || e.Show)
+}
+
+// Evaluate applies the rules in order against lookup. Later rules override the style and
+// icon set by earlier ones. The result is keyed by action target.
+func Evaluate(rules []*Rule, lookup Lookup) map[string]Effect {
+	effects := make(map[string]Effect)
+	for _, rule := range rules {
+		for _, action := range rule.Actions {
+			if action.Kind == ActionShow {
+				effect := effects[action.Target]
+				effect.Conditional = true
+				effects[action.Target] = effect
+			}
+		}
+
+		if !rule.when.eval(lookup) {
+			continue
+		}
+		for _, action := range rule.Actions {
+			effect := effects[action.Target]
+			switch action.Kind {
+			case ActionStyle:
+				effect.Style = action.Value
+			case ActionIcon:
+				effect.Icon = action.Value
+			case ActionShow:
+				effect.Show = true
+			case ActionHide:
+				effect.Hide = true
+			}
+			effects[action.Target] = effect
+		}
+	}
+	return effects
+}
+
+// ShownTargets lists the targets of show actions, whose data has to be collected even
+// when they are not enabled otherwise
+func ShownTargets(rules []*Rule) []string {
+	var targets []string
+	for _, rule := range rules {
+		for _, action := range rule.Actions {
+			if action.Kind == ActionShow {
+				targets = append(targets, action.Target)
+			}
+		}
+	}
+	return targets
+}
+
+// condition is a boolean expression over the snapshot
+type condition interface {
+	eval(lookup Lookup) bool
+}
+
+type allOf [2]condition
+
+func (c allOf) eval(lookup Lookup) bool { return c[0].eval(lookup) && c[1].eval(lookup) }
+
+type anyOf [2]condition
+
+func (c anyOf) eval(lookup Lookup) bool { return c[0].eval(lookup) || c[1].eval(lookup) }
+
+type negation struct{ inner condition }
+
+func (c negation) eval(lookup Lookup) bool { return !c.inner.eval(lookup) }
+
+// truthy holds when the value exists and is not false, zero or empty
+type truthy struct{ path string }
+
+func (c truthy) eval(lookup Lookup) bool {
+	value, ok := lookup(c.path)
+	if !ok {
+		return false
+	}
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if number, ok := toNumber(value); ok {
+		return number != 0
+	}
+	return true
+}
+
+// match holds when the value's text matches the pattern
+type match struct {
+	path    string
+	pattern *regexp.Regexp
+}
+
+func (c match) eval(lookup Lookup) bool {
+	value, ok := lookup(c.path)
+	return ok && value != nil && c.pattern.MatchString(toString(value))
+}
+
+// comparison compares a value with a literal: numerically when both are numbers,
+// otherwise as text. A missing value never compares.
+type comparison struct {
+	path    string
+	op      string
+	literal any // float64, string or bool
+}
+
+func (c comparison) eval(lookup Lookup) bool {
+	value, ok := lookup(c.path)
+	if !ok || value == nil {
+		return false
+	}
+
+	var order int
+	switch literal := c.literal.(type) {
+	case float64:
+		number, ok := toNumber(value)
+		if !ok {
+			return false
+		}
+		order = compare(number, literal)
+	case bool:
+		flag, ok := value.(bool)
+		if !ok || c.op != "==" && c.op != "!=" {
+			return false
+		}
+		order = compare(boolInt(flag), boolInt(literal))
+	case string:
+		order = strings.Compare(toString(value), literal)
+	}
+
+	switch c.op {
+	case "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	}
+	return false
+}
+
+func compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// toNumber converts numeric values, including numbers from the raw hook input
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// toString formats a value for text comparisons and patterns
+func toString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
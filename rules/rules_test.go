@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// snapshot is a lookup over fixed values
+func snapshot(values map[string]any) Lookup {
+	return func(path string) (any, bool) {
+		value, ok := values[path]
+		return value, ok
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{rule: "ctx.pct > 80 then show cost", want: `expected "when"`},
+		{rule: "when ctx.pct > then show cost", want: "expected a number"},
+		{rule: "when ctx.pct > 80 show cost", want: `expected "then"`},
+		{rule: "when ctx.pct > 80 then ctx.color = \"red\"", want: "unknown property"},
+		{rule: "when ctx.pct > 80 then ctx.style = red", want: "expected a quoted style"},
+		{rule: "when git.branch matches \"(\" then show cost", want: "invalid pattern"},
+		{rule: "when git.branch matches 5 then show cost", want: "matches needs a quoted pattern"},
+		{rule: "when ctx.pct > 80 then show cost hide git", want: `expected ","`},
+		{rule: "when ctx.pct > 80 then show", want: "expected a segment name"},
+		{rule: "when model.name == \"Opus then show cost", want: "unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	values := snapshot(map[string]any{
+		"ctx.pct":    85.5,
+		"git.branch": "main",
+		"git.dirty":  false,
+		"git.added":  12,
+		"cost.total": 2.5,
+		"model.name": "Opus",
+		"hook.extra": json.Number("7"),
+	})
+
+	tests := []struct {
+		name  string
+		rules []string
+		want  map[string]Effect
+	}{
+		{
+			name:  "numeric comparison",
+			rules: []string{`when ctx.pct > 80 then ctx.style = "bold red blink"`},
+			want:  map[string]Effect{"ctx": {Style: "bold red blink"}},
+		},
+		{
+			name:  "pattern",
+			rules: []string{`when git.branch matches "^(main|master)$" then git.style = "reverse", git.icon = "!"`},
+			want:  map[string]Effect{"git": {Style: "reverse", Icon: "!"}},
+		},
+		{
+			name:  "conditional segment stays hidden",
+			rules: []string{`when cost.total > 5 then show cost`},
+			want:  map[string]Effect{"cost": {Conditional: true}},
+		},
+		{
+			name:  "conditional segment shown",
+			rules: []string{`when cost.total >= 2.5 then show cost`},
+			want:  map[string]Effect{"cost": {Conditional: true, Show: true}},
+		},
+		{
+			name: "later rules win",
+			rules: []string{
+				`when ctx.pct > 50 then ctx.style = "yellow"`,
+				`when ctx.pct > 80 then ctx.style = "red"`,
+				`when ctx.pct > 90 then ctx.style = "blink"`,
+			},
+			want: map[string]Effect{"ctx": {Style: "red"}},
+		},
+		{
+			name:  "and binds tighter than or",
+			rules: []string{`when git.dirty and git.added > 0 or model.name == "Opus" then hide version`},
+			want:  map[string]Effect{"version": {Hide: true}},
+		},
+		{
+			name:  "not and booleans",
+			rules: []string{`when not git.dirty and git.dirty == false then hide git-changes`},
+			want:  map[string]Effect{"git-changes": {Hide: true}},
+		},
+		{
+			name: "missing and mistyped values never match",
+			rules: []string{
+				`when missing.value == 0 then hide a`,
+				`when git.branch > 5 then hide b`,
+				`when missing.value then hide c`,
+			},
+			want: map[string]Effect{},
+		},
+		{
+			name:  "raw hook numbers",
+			rules: []string{`when hook.extra < -1 or hook.extra == 7 then hide model`},
+			want:  map[string]Effect{"model": {Hide: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, errs := ParseAll(tt.rules)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if got := Evaluate(parsed, values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectVisible(t *testing.T) {
+	tests := []struct {
+		effect Effect
+		want   bool
+	}{
+		{effect: Effect{}, want: true},
+		{effect: Effect{Hide: true}, want: false},
+		{effect: Effect{Conditional: true}, want: false},
+		{effect: Effect{Conditional: true, Show: true}, want: true},
+		{effect: Effect{Conditional: true, Show: true, Hide: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.effect.Visible(); got != tt.want {
+			t.Errorf("%+v.Visible() = %v, want %v", tt.effect, got, tt.want)
+		}
+	}
+}
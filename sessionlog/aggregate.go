@@ -0,0 +1,134 @@
+package sessionlog
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Grouping is what a report groups entries by
+type Grouping string
+
+const (
+	ByDay     Grouping = "day"
+	ByWeek    Grouping = "week"
+	ByProject Grouping = "project"
+	ByModel   Grouping = "model"
+)
+
+// Groupings lists the valid groupings
+var Groupings = []Grouping{ByDay, ByWeek, ByProject, ByModel}
+
+// Totals sums the usage of a group of entries
+type Totals struct {
+	Key      string // Day (2006-01-02), ISO week (2006-W01), project directory or model
+	Sessions int
+	Requests int
+	Tokens   Tokens
+	CostUSD  float64
+
+	// Duration adds up, for each session, the time between its first and last response in the group
+	Duration time.Duration
+}
+
+// span is the first and last response of one session within a group
+type span struct {
+	first, last time.Time
+}
+
+// accumulator builds the totals of one group
+type accumulator struct {
+	totals   Totals
+	sessions map[string]span
+}
+
+func (a *accumulator) add(entry Entry) {
+	a.totals.Requests++
+	a.totals.Tokens.add(entry.Tokens)
+	a.totals.CostUSD += entry.CostUSD
+
+	s, ok := a.sessions[entry.SessionID]
+	if !ok {
+		s = span{first: entry.Time, last: entry.Time}
+	}
+	if entry.Time.Before(s.first) {
+		s.first = entry.Time
+	}
+	if entry.Time.After(s.last) {
+		s.last = entry.Time
+	}
+	a.sessions[entry.SessionID] = s
+}
+
+func (a *accumulator) result() Totals {
+	totals := a.totals
+	totals.Sessions = len(a.sessions)
+	for _, s := range a.sessions {
+		totals.Duration += s.last.Sub(s.first)
+	}
+	return totals
+}
+
+// Sum totals every entry under key
+func Sum(key string, entries []Entry) Totals {
+	acc := accumulator{totals: Totals{Key: key}, sessions: make(map[string]span)}
+	for _, entry := range entries {
+		acc.add(entry)
+	}
+	return acc.result()
+}
+
+// Aggregate groups entries by day or week in loc, project or model. Days and weeks are
+// sorted in time order, projects and models by descending cost.
+func Aggregate(entries []Entry, by Grouping, loc *time.Location) []Totals {
+	groups := make(map[string]*accumulator)
+	for _, entry := range entries {
+		key := groupKey(entry, by, loc)
+		acc, ok := groups[key]
+		if !ok {
+			acc = &accumulator{totals: Totals{Key: key}, sessions: make(map[string]span)}
+			groups[key] = acc
+		}
+		acc.add(entry)
+	}
+
+	rows := make([]Totals, 0, len(groups))
+	for _, acc := range groups {
+		rows = append(rows, acc.result())
+	}
+	slices.SortFunc(rows, func(a, b Totals) int {
+		if by == ByProject || by == ByModel {
+			if c := cmp.Compare(b.CostUSD, a.CostUSD); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return rows
+}
+
+// groupKey returns the group entry belongs to
+func groupKey(entry Entry, by Grouping, loc *time.Location) string {
+	switch by {
+	case ByWeek:
+		year, week := entry.Time.In(loc).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case ByProject:
+		return entry.Project
+	case ByModel:
+		return entry.Model
+	default:
+		return entry.Time.In(loc).Format(time.DateOnly)
+	}
+}
+
+// PeriodStart returns the start of the day or ISO week containing t, in t's location
+func PeriodStart(t time.Time, by Grouping) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if by == ByWeek {
+		// Weeks start on Monday
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	}
+	return start
+}
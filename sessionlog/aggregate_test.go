@@ -0,0 +1,66 @@
+package sessionlog
+
+import (
+	"testing"
+	"time"
+)
+
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestAggregate(t *testing.T) {
+	entries := []Entry{
+		{Time: at(t, "2026-03-01T23:50:00Z"), SessionID: "a", Project: "/work/api", Model: "opus", Tokens: Tokens{Input: 10}, CostUSD: 1},
+		{Time: at(t, "2026-03-02T00:20:00Z"), SessionID: "a", Project: "/work/api", Model: "opus", Tokens: Tokens{Input: 10}, CostUSD: 1},
+		{Time: at(t, "2026-03-02T09:00:00Z"), SessionID: "b", Project: "/work/web", Model: "sonnet", Tokens: Tokens{Output: 5}, CostUSD: 3},
+		{Time: at(t, "2026-03-02T10:30:00Z"), SessionID: "b", Project: "/work/web", Model: "sonnet", Tokens: Tokens{Output: 5}, CostUSD: 3},
+	}
+
+	days := Aggregate(entries, ByDay, time.UTC)
+	if len(days) != 2 || days[0].Key != "2026-03-01" || days[1].Key != "2026-03-02" {
+		t.Fatalf("days = %+v", days)
+	}
+	if days[1].Sessions != 2 || days[1].Requests != 3 || days[1].CostUSD != 7 {
+		t.Errorf("second day = %+v, want 2 sessions, 3 requests, $7", days[1])
+	}
+	// Session a only spans its one response on the second day
+	if days[1].Duration != 90*time.Minute {
+		t.Errorf("second day duration = %s, want 1h30m", days[1].Duration)
+	}
+
+	// Sunday and Monday fall in different ISO weeks
+	if weeks := Aggregate(entries, ByWeek, time.UTC); len(weeks) != 2 || weeks[0].Key != "2026-W09" || weeks[1].Key != "2026-W10" {
+		t.Errorf("weeks = %+v", weeks)
+	}
+
+	projects := Aggregate(entries, ByProject, time.UTC)
+	if len(projects) != 2 || projects[0].Key != "/work/web" {
+		t.Errorf("projects = %+v, want the most expensive first", projects)
+	}
+
+	total := Sum("total", entries)
+	if total.Sessions != 2 || total.Tokens.Total() != 30 || total.Duration != 2*time.Hour {
+		t.Errorf("total = %+v, want 2 sessions, 30 tokens, 2h", total)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := at(t, "2026-03-05T15:04:05Z") // A Thursday
+
+	if got := PeriodStart(now, ByDay); !got.Equal(at(t, "2026-03-05T00:00:00Z")) {
+		t.Errorf("day start = %s", got)
+	}
+	if got := PeriodStart(now, ByWeek); !got.Equal(at(t, "2026-03-02T00:00:00Z")) {
+		t.Errorf("week start = %s, want Monday", got)
+	}
+	if got := PeriodStart(at(t, "2026-03-08T10:00:00Z"), ByWeek); !got.Equal(at(t, "2026-03-02T00:00:00Z")) {
+		t.Errorf("week start on Sunday = %s, want the Monday before", got)
+	}
+}
@@ -0,0 +1,99 @@
+package sessionlog
+
+import "time"
+
+// BlockLength is how long the rolling usage windows of subscription plans last
+const BlockLength = 5 * time.Hour
+
+// Block is one usage window: it opens at the hour of the first response sent while no
+// window was open and lasts BlockLength
+type Block struct {
+	Start    time.Time
+	First    time.Time // First response in the block
+	Last     time.Time // Latest response in the block
+	Requests int
+	Tokens   Tokens
+	CostUSD  float64
+}
+
+// End returns when the block closes
+func (b Block) End() time.Time {
+	return b.Start.Add(BlockLength)
+}
+
+// Blocks splits entries, oldest first, into usage blocks
+func Blocks(entries []Entry) []Block {
+	var blocks []Block
+	for _, entry := range entries {
+		if len(blocks) == 0 || !entry.Time.Before(blocks[len(blocks)-1].End()) {
+			blocks = append(blocks, Block{Start: entry.Time.Truncate(time.Hour), First: entry.Time})
+		}
+
+		block := &blocks[len(blocks)-1]
+		block.Last = entry.Time
+		block.Requests++
+		block.Tokens.add(entry.Tokens)
+		block.CostUSD += entry.CostUSD
+	}
+	return blocks
+}
+
+// CurrentBlock returns the block open at now, nil if there is none, and the most tokens any
+// earlier block used
+func CurrentBlock(entries []Entry, now time.Time) (*Block, int) {
+	var current *Block
+	peak := 0
+	for _, block := range Blocks(entries) {
+		if !now.Before(block.Start) && now.Before(block.End()) {
+			current = &block
+			continue
+		}
+		peak = max(peak, block.Tokens.Total())
+	}
+	return current, peak
+}
+
+// BlockStatus is where a block stands at some moment
+type BlockStatus struct {
+	Start     time.Time
+	Remaining time.Duration
+	Tokens    int // Used so far
+	Projected int // Expected by the end of the block if usage keeps its current rate
+	Limit     int // Tokens the block is measured against, 0 if unknown
+}
+
+// Status returns where the block stands at now, measured against limit. The burn rate is
+// taken since the block's first response, at least a minute ago so a single response
+// doesn't project wildly.
+func (b Block) Status(now time.Time, limit int) BlockStatus {
+	tokens := b.Tokens.Total()
+	status := BlockStatus{
+		Start:     b.Start,
+		Remaining: max(b.End().Sub(now), 0),
+		Tokens:    tokens,
+		Projected: tokens,
+		Limit:     limit,
+	}
+
+	elapsed := max(now.Sub(b.First), time.Minute)
+	rate := float64(tokens) / elapsed.Seconds()
+	status.Projected += int(rate * status.Remaining.Seconds())
+	return status
+}
+
+// Percent returns the share of the limit used so far, 0 without a limit
+func (s BlockStatus) Percent() float64 {
+	return s.percentOf(s.Tokens)
+}
+
+// ProjectedPercent returns the share of the limit the block is expected to use, 0 without a limit
+func (s BlockStatus) ProjectedPercent() float64 {
+	return s.percentOf(s.Projected)
+}
+
+func (s BlockStatus) percentOf(tokens int) float64 {
+	if s.Limit <= 0 {
+		return 0
+	}
+	return float64(tokens) / float64(s.Limit) * 100
+}
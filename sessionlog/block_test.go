@@ -0,0 +1,47 @@
+package sessionlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCurrentBlock(t *testing.T) {
+	response := func(timestamp string, tokens int) Entry {
+		return Entry{Time: at(t, timestamp), Tokens: Tokens{Output: tokens}}
+	}
+	entries := []Entry{
+		// An earlier block: 08:00 to 13:00
+		response("2026-03-02T08:40:00Z", 300),
+		response("2026-03-02T12:59:00Z", 500),
+		// The next response after 13:00 opens a new block at its hour
+		response("2026-03-02T14:30:00Z", 100),
+		response("2026-03-02T15:30:00Z", 200),
+	}
+
+	blocks := Blocks(entries)
+	if len(blocks) != 2 || !blocks[0].Start.Equal(at(t, "2026-03-02T08:00:00Z")) || !blocks[1].Start.Equal(at(t, "2026-03-02T14:00:00Z")) {
+		t.Fatalf("Blocks() = %+v", blocks)
+	}
+
+	now := at(t, "2026-03-02T16:30:00Z")
+	current, peak := CurrentBlock(entries, now)
+	if current == nil || current.Tokens.Total() != 300 || peak != 800 {
+		t.Fatalf("CurrentBlock() = %+v, %d; want the 14:00 block and a peak of 800", current, peak)
+	}
+
+	status := current.Status(now, 1000)
+	if status.Remaining != 2*time.Hour+30*time.Minute {
+		t.Errorf("remaining = %s, want 2h30m", status.Remaining)
+	}
+	// 300 tokens in the 2h since the first response, so 375 more in the 2h30m left
+	if status.Projected != 675 || status.Percent() != 30 || status.ProjectedPercent() != 67.5 {
+		t.Errorf("status = %+v (%.1f%%, projected %.1f%%)", status, status.Percent(), status.ProjectedPercent())
+	}
+
+	if current, _ := CurrentBlock(entries, at(t, "2026-03-02T19:00:00Z")); current != nil {
+		t.Errorf("CurrentBlock() after the block closed = %+v, want nil", current)
+	}
+	if got := (BlockStatus{Tokens: 10}).Percent(); got != 0 {
+		t.Errorf("Percent() without a limit = %v, want 0", got)
+	}
+}
@@ -0,0 +1,172 @@
+package sessionlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/cachefile"
+)
+
+// Cache defaults, see Cache.TTL and Cache.Deadline
+const (
+	DefaultCacheTTL      = time.Minute
+	DefaultCacheDeadline = 25 * time.Millisecond
+)
+
+// cacheMaxAge is how long usage of a log directory nobody asks about stays in a persisted cache
+const cacheMaxAge = 24 * time.Hour
+
+// Cache keeps the usage read from each log directory, since reading a week of logs on every
+// render would be slow. Usage older than the TTL is read again in the background: Get waits
+// for it until the deadline and otherwise returns the older usage. With a path, usage is
+// persisted there so short-lived processes share it.
+type Cache struct {
+	TTL      time.Duration // How long usage is reused before the logs are read again
+	Deadline time.Duration // How long Get waits for a read in progress
+
+	path string
+	read func(dir string, now time.Time) (Usage, error)
+
+	mu       sync.Mutex
+	loaded   bool
+	entries  map[string]cacheEntry    // Keyed by log directory
+	pending  map[string]chan struct{} // Reads in progress, closed when done
+	inFlight sync.WaitGroup
+}
+
+// cacheEntry is the last read of one log directory
+type cacheEntry struct {
+	Usage Usage
+	Error string `json:",omitempty"`
+}
+
+// NewCache creates a cache persisted at path, or an in-memory cache if path is empty
+func NewCache(path string) *Cache {
+	return &Cache{
+		TTL:      DefaultCacheTTL,
+		Deadline: DefaultCacheDeadline,
+		path:     path,
+		read:     ReadUsage,
+		entries:  make(map[string]cacheEntry),
+		pending:  make(map[string]chan struct{}),
+	}
+}
+
+// DefaultCachePath returns the usage cache location inside the user cache directory
+func DefaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "cc-status-line", "usage.json")
+}
+
+// Get returns the usage of the logs under dir at now, nil when they have never been read
+// and the first read outlasts the deadline
+func (c *Cache) Get(dir string, now time.Time) (*Usage, error) {
+	c.mu.Lock()
+	c.load()
+	entry, ok := c.entries[dir]
+	if ok && now.Sub(entry.Usage.ReadAt) < c.TTL {
+		c.mu.Unlock()
+		return entry.result()
+	}
+
+	done, reading := c.pending[dir]
+	if !reading {
+		done = make(chan struct{})
+		c.pending[dir] = done
+		c.inFlight.Add(1)
+		go c.refresh(dir, now, done)
+	}
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.Deadline)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+
+	c.mu.Lock()
+	entry, ok = c.entries[dir]
+	c.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	return entry.result()
+}
+
+// Wait blocks until the reads in progress are done, so a process can finish one that
+// outlasted its render before exiting
+func (c *Cache) Wait() {
+	c.inFlight.Wait()
+}
+
+// refresh reads the logs under dir and stores the result, failures included, so broken
+// logs aren't read again before the TTL
+func (c *Cache) refresh(dir string, now time.Time, done chan struct{}) {
+	defer c.inFlight.Done()
+	defer close(done)
+
+	var entry cacheEntry
+	func() {
+		// Nothing up the stack recovers a panic in this goroutine
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				entry = cacheEntry{Error: fmt.Sprintf("panic reading usage: %v", recovered)}
+			}
+		}()
+		usage, err := c.read(dir, now)
+		entry = cacheEntry{Usage: usage}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+	}()
+	entry.Usage.ReadAt = now
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[dir] = entry
+	delete(c.pending, dir)
+	c.save()
+}
+
+func (e cacheEntry) result() (*Usage, error) {
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+	return &e.Usage, nil
+}
+
+// load reads the persisted usage once. A missing or corrupt file starts an empty cache.
+func (c *Cache) load() {
+	if c.loaded || c.path == "" {
+		return
+	}
+	c.loaded = true
+
+	_ = cachefile.Load(c.path, &c.entries)
+	if c.entries == nil {
+		c.entries = make(map[string]cacheEntry)
+	}
+}
+
+// save persists the usage, dropping directories not read for a day. Errors are ignored: a
+// lost cache only means the logs are read again.
+func (c *Cache) save() {
+	if c.path == "" {
+		return
+	}
+
+	for key, entry := range c.entries {
+		if time.Since(entry.Usage.ReadAt) > cacheMaxAge {
+			delete(c.entries, key)
+		}
+	}
+	_ = cachefile.Save(c.path, c.entries)
+}
@@ -0,0 +1,159 @@
+package sessionlog
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheReadsLogs(t *testing.T) {
+	dir := t.TempDir()
+	writeUsageLogs(t, dir)
+
+	cache := NewCache("")
+	cache.Deadline = time.Minute
+	usage, err := cache.Get(dir, at(t, "2026-03-04T10:00:00Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage == nil || usage.Day.Requests != 2 || usage.Block == nil {
+		t.Errorf("Get() = %+v, want today's usage and the open block", usage)
+	}
+}
+
+func TestCachePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	now := time.Now()
+	block := &Block{Start: now.Truncate(time.Hour), First: now.Add(-time.Minute), Last: now, Tokens: Tokens{Output: 500}}
+
+	cache := NewCache(path)
+	cache.Deadline = time.Minute
+	cache.read = func(dir string, now time.Time) (Usage, error) {
+		return Usage{ReadAt: now, Day: Totals{Key: "day", Requests: 2}, Block: block, Peak: 4000}, nil
+	}
+	if _, err := cache.Get("/logs", now); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second process reuses the usage without reading the logs
+	reloaded := NewCache(path)
+	reloaded.read = func(string, time.Time) (Usage, error) {
+		t.Error("read the logs again before the TTL")
+		return Usage{}, nil
+	}
+	usage, err := reloaded.Get("/logs", now.Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage == nil || usage.Day.Requests != 2 || usage.Peak != 4000 {
+		t.Fatalf("Get() = %+v, want the persisted usage", usage)
+	}
+	if usage.Block == nil || !usage.Block.Start.Equal(block.Start) || usage.Block.Tokens != block.Tokens {
+		t.Errorf("Block = %+v, want %+v", usage.Block, block)
+	}
+	reloaded.Wait()
+}
+
+func TestCacheReturnsStaleUsagePastDeadline(t *testing.T) {
+	now := time.Now()
+	release := make(chan struct{})
+
+	cache := NewCache("")
+	cache.Deadline = time.Millisecond
+	cache.entries["/logs"] = cacheEntry{Usage: Usage{ReadAt: now.Add(-2 * time.Minute), Day: Totals{Requests: 1}}}
+	cache.read = func(dir string, now time.Time) (Usage, error) {
+		<-release
+		return Usage{Day: Totals{Requests: 5}}, nil
+	}
+
+	usage, err := cache.Get("/logs", now)
+	if err != nil || usage == nil || usage.Day.Requests != 1 {
+		t.Fatalf("Get() during a slow read = %+v, %v; want the stale usage", usage, err)
+	}
+	// A second render doesn't start another read
+	if usage, _ := cache.Get("/logs", now); usage == nil || usage.Day.Requests != 1 {
+		t.Errorf("second Get() = %+v, want the stale usage", usage)
+	}
+
+	close(release)
+	cache.Wait()
+	usage, err = cache.Get("/logs", now)
+	if err != nil || usage == nil || usage.Day.Requests != 5 {
+		t.Errorf("Get() after the read = %+v, %v; want the fresh usage", usage, err)
+	}
+}
+
+func TestCacheFirstReadPastDeadline(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewCache("")
+	cache.Deadline = time.Millisecond
+	cache.read = func(string, time.Time) (Usage, error) {
+		<-release
+		return Usage{}, nil
+	}
+
+	if usage, err := cache.Get("/logs", time.Now()); usage != nil || err != nil {
+		t.Errorf("Get() = %+v, %v; want nothing before the first read finishes", usage, err)
+	}
+	close(release)
+	cache.Wait()
+}
+
+func TestCacheKeepsErrors(t *testing.T) {
+	reads := 0
+	cache := NewCache(filepath.Join(t.TempDir(), "usage.json"))
+	cache.Deadline = time.Minute
+	cache.read = func(string, time.Time) (Usage, error) {
+		reads++
+		return Usage{}, errors.New("permission denied")
+	}
+
+	now := time.Now()
+	for range 2 {
+		if usage, err := cache.Get("/logs", now); err == nil || usage != nil {
+			t.Errorf("Get() = %+v, %v; want the read error", usage, err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("read the logs %d times, want once within the TTL", reads)
+	}
+
+	cache.read = func(string, time.Time) (Usage, error) { panic("boom") }
+	if _, err := cache.Get("/logs", now.Add(2*time.Minute)); err == nil {
+		t.Error("Get() after a panicking read returned no error")
+	}
+}
+
+func TestCachedBlockCloses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	now := time.Now()
+	start := now.Add(-4 * time.Hour)
+	block := &Block{Start: start, First: start, Last: now.Add(-time.Minute), Tokens: Tokens{Output: 500}}
+
+	cache := NewCache(path)
+	cache.Deadline = time.Minute
+	cache.read = func(dir string, now time.Time) (Usage, error) {
+		return Usage{ReadAt: now, Block: block, Peak: 1000}, nil
+	}
+	usage, err := cache.Get("/logs", now)
+	if err != nil || usage.BlockStatus(now, 0) == nil {
+		t.Fatalf("Get() = %+v, %v; want the open block", usage, err)
+	}
+
+	// Within the TTL the persisted usage is reused, but the block closed in the meantime
+	reloaded := NewCache(path)
+	reloaded.TTL = 2 * time.Hour
+	reloaded.read = func(string, time.Time) (Usage, error) {
+		t.Error("read the logs again before the TTL")
+		return Usage{}, nil
+	}
+	later := start.Add(5*time.Hour + time.Minute)
+	usage, err = reloaded.Get("/logs", later)
+	if err != nil || usage == nil {
+		t.Fatalf("Get() = %+v, %v; want the persisted usage", usage, err)
+	}
+	if status := usage.BlockStatus(later, 0); status != nil {
+		t.Errorf("BlockStatus() after the block closed = %+v, want nil", status)
+	}
+}
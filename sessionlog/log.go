@@ -0,0 +1,181 @@
+// Package sessionlog reads the session logs Claude Code keeps under ~/.claude/projects and sums
+// tokens, cost and session time across sessions.
+package sessionlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Entry is one API response recorded in a session log
+type Entry struct {
+	Time      time.Time
+	SessionID string
+	Project   string // Working directory of the session
+	Model     string
+	Tokens    Tokens
+	CostUSD   float64 // Cost logged by Claude Code, or an estimate from list prices
+}
+
+// Tokens counts the tokens of one or more API responses
+type Tokens struct {
+	Input         int `json:"input"`
+	Output        int `json:"output"`
+	CacheCreation int `json:"cache_creation"`
+	CacheRead     int `json:"cache_read"`
+}
+
+// Total returns every token counted, cache reads included
+func (t Tokens) Total() int {
+	return t.Input + t.Output + t.CacheCreation + t.CacheRead
+}
+
+// add adds other's counts to t
+func (t *Tokens) add(other Tokens) {
+	t.Input += other.Input
+	t.Output += other.Output
+	t.CacheCreation += other.CacheCreation
+	t.CacheRead += other.CacheRead
+}
+
+// logLine is the part of a session log line usage needs
+type logLine struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"sessionId"`
+	Cwd       string    `json:"cwd"`
+	RequestID string    `json:"requestId"`
+	CostUSD   *float64  `json:"costUSD"` // Only written by older Claude Code versions
+	Message   struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// syntheticModel marks messages Claude Code made up itself, e.g. for API errors
+const syntheticModel = "<synthetic>"
+
+// ProjectsDir returns the directory Claude Code keeps session logs in:
+// $CLAUDE_CONFIG_DIR/projects, or ~/.claude/projects
+func ProjectsDir(getenv func(string) string, home string) string {
+	if dir := getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "projects")
+	}
+	return filepath.Join(home, ".claude", "projects")
+}
+
+// Read returns the API responses logged under dir at or after since, oldest first. Files
+// last written before since aren't opened. Unreadable files and malformed lines are
+// skipped; only a missing or unreadable dir is an error.
+func Read(dir string, since time.Time) ([]Entry, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	seen := make(map[string]int) // Message and request ID to index in entries
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".jsonl" {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		// Sessions started without a working directory are grouped under their log directory
+		project := filepath.Base(filepath.Dir(path))
+		readLog(file, project, since, func(id string, entry Entry) {
+			// Claude Code writes a line per content block of a response, each with its usage
+			if id != "" {
+				if i, ok := seen[id]; ok {
+					entries[i] = entry
+					return
+				}
+				seen[id] = len(entries)
+			}
+			entries = append(entries, entry)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(entries, func(a, b Entry) int { return a.Time.Compare(b.Time) })
+	return entries, nil
+}
+
+// readLog calls add for every API response in a session log, with an ID identifying the
+// response across lines. Lines can be large, so they are read whole rather than scanned.
+func readLog(r io.Reader, project string, since time.Time, add func(id string, entry Entry)) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && bytes.Contains(line, []byte(`"usage"`)) {
+			if entry, id, ok := parseLine(line, project); ok && !entry.Time.Before(since) {
+				add(id, entry)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseLine decodes an assistant line into an entry
+func parseLine(line []byte, project string) (Entry, string, bool) {
+	var parsed logLine
+	if err := json.Unmarshal(line, &parsed); err != nil {
+		return Entry{}, "", false
+	}
+	usage := parsed.Message.Usage
+	if parsed.Type != "assistant" || usage == nil || parsed.Message.Model == syntheticModel {
+		return Entry{}, "", false
+	}
+
+	entry := Entry{
+		Time:      parsed.Timestamp,
+		SessionID: parsed.SessionID,
+		Project:   parsed.Cwd,
+		Model:     parsed.Message.Model,
+		Tokens: Tokens{
+			Input:         usage.InputTokens,
+			Output:        usage.OutputTokens,
+			CacheCreation: usage.CacheCreationInputTokens,
+			CacheRead:     usage.CacheReadInputTokens,
+		},
+	}
+	if entry.Project == "" {
+		entry.Project = project
+	}
+	if parsed.CostUSD != nil {
+		entry.CostUSD = *parsed.CostUSD
+	} else {
+		entry.CostUSD = EstimateCost(entry.Model, entry.Tokens)
+	}
+
+	id := ""
+	if parsed.Message.ID != "" {
+		id = parsed.Message.ID + ":" + parsed.RequestID
+	}
+	return entry, id, true
+}
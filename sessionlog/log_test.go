@@ -0,0 +1,104 @@
+package sessionlog
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// assistantLine is a session log line for one API response
+func assistantLine(session, cwd, model, id, timestamp string, input, output int) string {
+	return fmt.Sprintf(`{"type":"assistant","sessionId":%q,"cwd":%q,"requestId":"req_%s","timestamp":%q,`+
+		`"message":{"id":"msg_%s","model":%q,"usage":{"input_tokens":%d,"output_tokens":%d,`+
+		`"cache_creation_input_tokens":0,"cache_read_input_tokens":1000}}}`,
+		session, cwd, id, timestamp, id, model, input, output)
+}
+
+func writeLog(t *testing.T, path string, lines ...string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	writeLog(t, filepath.Join(dir, "-work-api", "a.jsonl"),
+		`{"type":"user","sessionId":"a","timestamp":"2026-03-02T09:00:00Z","message":{"role":"user","content":"hi"}}`,
+		assistantLine("a", "/work/api", "claude-sonnet-4", "1", "2026-03-02T09:00:05Z", 100, 10),
+		// A second content block of the same response repeats it with the final usage
+		assistantLine("a", "/work/api", "claude-sonnet-4", "1", "2026-03-02T09:00:06Z", 100, 50),
+		assistantLine("a", "/work/api", "<synthetic>", "2", "2026-03-02T09:01:00Z", 0, 0),
+		`{"type":"assistant", broken`,
+		assistantLine("a", "", "claude-opus-4-1", "3", "2026-03-02T09:30:00Z", 10, 10),
+	)
+	writeLog(t, filepath.Join(dir, "-work-web", "b.jsonl"),
+		`{"type":"assistant","sessionId":"b","cwd":"/work/web","costUSD":0.5,"timestamp":"2026-03-01T18:00:00Z",`+
+			`"message":{"model":"claude-3-5-sonnet","usage":{"input_tokens":5,"output_tokens":5}}}`,
+	)
+
+	entries, err := Read(dir, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Read() = %d entries, want 3: %+v", len(entries), entries)
+	}
+
+	if entries[0].SessionID != "b" || entries[0].CostUSD != 0.5 {
+		t.Errorf("first entry = %+v, want session b with its logged cost", entries[0])
+	}
+	if got := entries[1].Tokens; got.Output != 50 || got.CacheRead != 1000 {
+		t.Errorf("repeated response tokens = %+v, want the last line's", got)
+	}
+	if want := (100*3 + 50*15 + 1000*0.3) / 1e6; math.Abs(entries[1].CostUSD-want) > 1e-12 {
+		t.Errorf("estimated cost = %v, want %v", entries[1].CostUSD, want)
+	}
+	if entries[2].Project != "-work-api" {
+		t.Errorf("project without cwd = %q, want the log directory", entries[2].Project)
+	}
+
+	since, _ := time.Parse(time.RFC3339, "2026-03-02T09:10:00Z")
+	if entries, _ := Read(dir, since); len(entries) != 1 {
+		t.Errorf("Read(since) = %+v, want only the last response", entries)
+	}
+
+	if _, err := Read(filepath.Join(dir, "missing"), time.Time{}); !os.IsNotExist(err) {
+		t.Errorf("Read(missing dir) error = %v, want not exist", err)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	tokens := Tokens{Input: 1_000_000, Output: 1_000_000}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{model: "claude-3-opus-20240229", want: 90},
+		{model: "claude-opus-4-20250514", want: 90},
+		{model: "claude-opus-4-1-20250805", want: 90},
+		{model: "claude-opus-4-5-20251101", want: 30},
+		{model: "claude-opus-4-6", want: 30},
+		{model: "claude-opus-5-20270101", want: 30},
+		{model: "claude-sonnet-4-5-20250929", want: 18},
+		{model: "claude-3-5-haiku-20241022", want: 4.8},
+		{model: "claude-3-haiku-20240307", want: 1.5},
+		{model: "claude-haiku-4-5-20251001", want: 6},
+		{model: "gpt-4o", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateCost(tt.model, tokens); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EstimateCost(%q) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+}
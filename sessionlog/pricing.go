@@ -0,0 +1,44 @@
+package sessionlog
+
+import "strings"
+
+// price is a model's API list price in USD per million tokens
+type price struct {
+	input, output, cacheWrite, cacheRead float64
+}
+
+// prices maps fragments of model names to list prices, most specific first. Older versions
+// priced differently are listed by name; any other version, including ones released after
+// this list, gets its family's current price.
+var prices = []struct {
+	fragment string
+	price    price
+}{
+	{fragment: "3-opus", price: legacyOpus},
+	{fragment: "opus-4-20", price: legacyOpus}, // Opus 4, named by date alone
+	{fragment: "opus-4-1", price: legacyOpus},
+	{fragment: "opus", price: price{input: 5, output: 25, cacheWrite: 6.25, cacheRead: 0.5}},
+	{fragment: "3-5-haiku", price: price{input: 0.8, output: 4, cacheWrite: 1, cacheRead: 0.08}},
+	{fragment: "3-haiku", price: price{input: 0.25, output: 1.25, cacheWrite: 0.3, cacheRead: 0.03}},
+	{fragment: "haiku", price: price{input: 1, output: 5, cacheWrite: 1.25, cacheRead: 0.1}},
+	{fragment: "sonnet", price: price{input: 3, output: 15, cacheWrite: 3.75, cacheRead: 0.3}},
+}
+
+// legacyOpus is the price of Opus models before 4.5
+var legacyOpus = price{input: 15, output: 75, cacheWrite: 18.75, cacheRead: 1.5}
+
+// EstimateCost prices tokens at the model's API list price. It is an estimate: subscription
+// plans don't bill per token, and unknown models cost nothing.
+func EstimateCost(model string, tokens Tokens) float64 {
+	model = strings.ToLower(model)
+	for _, entry := range prices {
+		if strings.Contains(model, entry.fragment) {
+			p := entry.price
+			return (float64(tokens.Input)*p.input +
+				float64(tokens.Output)*p.output +
+				float64(tokens.CacheCreation)*p.cacheWrite +
+				float64(tokens.CacheRead)*p.cacheRead) / 1e6
+		}
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package sessionlog
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"time"
+)
+
+// History is how far back ReadUsage reads: the whole current week, and the earlier blocks
+// the current one is compared against
+const History = 7 * 24 * time.Hour
+
+// Usage is what the usage segments show, as read from the logs at one moment
+type Usage struct {
+	ReadAt time.Time
+	Day    Totals // Since the start of ReadAt's day
+	Week   Totals // Since the start of ReadAt's week
+	Block  *Block // Open at ReadAt, nil if none was
+	Peak   int    // Most tokens an earlier block used
+}
+
+// ReadUsage reads the logs under dir for what the usage segments show at now. A missing
+// directory means nothing was used.
+func ReadUsage(dir string, now time.Time) (Usage, error) {
+	entries, err := Read(dir, now.Add(-History))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Usage{}, err
+	}
+
+	usage := Usage{
+		ReadAt: now,
+		Day:    Sum(string(ByDay), since(entries, PeriodStart(now, ByDay))),
+		Week:   Sum(string(ByWeek), since(entries, PeriodStart(now, ByWeek))),
+	}
+	usage.Block, usage.Peak = CurrentBlock(entries, now)
+	return usage, nil
+}
+
+// since returns the entries, oldest first, from start on
+func since(entries []Entry, start time.Time) []Entry {
+	i, _ := slices.BinarySearchFunc(entries, start, func(entry Entry, start time.Time) int {
+		return entry.Time.Compare(start)
+	})
+	return entries[i:]
+}
+
+// Totals returns the day or week totals at now. A period that began after the logs were
+// read has nothing in it yet.
+func (u Usage) Totals(by Grouping, now time.Time) Totals {
+	if !PeriodStart(u.ReadAt, by).Equal(PeriodStart(now, by)) {
+		return Totals{Key: string(by)}
+	}
+	if by == ByWeek {
+		return u.Week
+	}
+	return u.Day
+}
+
+// BlockStatus returns where the block open when the logs were read stands at now, nil if
+// none was or it has closed since. A limit of 0 measures it against the largest earlier block.
+func (u Usage) BlockStatus(now time.Time, limit int) *BlockStatus {
+	if u.Block == nil || !now.Before(u.Block.End()) {
+		return nil
+	}
+	if limit == 0 {
+		limit = u.Peak
+	}
+	status := u.Block.Status(now, limit)
+	return &status
+}
@@ -0,0 +1,98 @@
+package sessionlog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeUsageLogs writes responses across two weeks and three blocks, the last one open at
+// 2026-03-04T10:00:00Z, a Wednesday
+func writeUsageLogs(t *testing.T, dir string) {
+	t.Helper()
+
+	writeLog(t, filepath.Join(dir, "-work-api", "a.jsonl"),
+		assistantLine("a", "/work/api", "claude-sonnet-4", "1", "2026-03-01T23:00:00Z", 10, 1000),
+		assistantLine("a", "/work/api", "claude-sonnet-4", "2", "2026-03-02T10:00:00Z", 10, 200),
+	)
+	writeLog(t, filepath.Join(dir, "-work-web", "b.jsonl"),
+		assistantLine("b", "/work/web", "claude-sonnet-4", "3", "2026-03-04T08:10:00Z", 10, 50),
+		assistantLine("b", "/work/web", "claude-sonnet-4", "4", "2026-03-04T09:00:00Z", 10, 70),
+	)
+}
+
+func TestReadUsage(t *testing.T) {
+	dir := t.TempDir()
+	writeUsageLogs(t, dir)
+	now := at(t, "2026-03-04T10:00:00Z")
+
+	usage, err := ReadUsage(dir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if usage.Day.Key != "day" || usage.Day.Requests != 2 || usage.Day.Tokens.Output != 120 {
+		t.Errorf("Day = %+v, want today's 2 responses", usage.Day)
+	}
+	if usage.Week.Requests != 3 || usage.Week.Sessions != 2 {
+		t.Errorf("Week = %+v, want 3 responses in 2 sessions since Monday", usage.Week)
+	}
+	if usage.Block == nil || !usage.Block.Start.Equal(at(t, "2026-03-04T08:00:00Z")) || usage.Block.Requests != 2 {
+		t.Errorf("Block = %+v, want the block opened at 08:00", usage.Block)
+	}
+	// The Sunday night block used 10 + 1000 output + 1000 cache read tokens
+	if usage.Peak != 2010 {
+		t.Errorf("Peak = %d, want 2010", usage.Peak)
+	}
+
+	// A missing directory means nothing was used
+	usage, err = ReadUsage(filepath.Join(dir, "missing"), now)
+	if err != nil || usage.Day.Requests != 0 || usage.Block != nil {
+		t.Errorf("ReadUsage(missing) = %+v, %v; want empty usage", usage, err)
+	}
+}
+
+func TestUsageTotals(t *testing.T) {
+	usage := Usage{
+		ReadAt: at(t, "2026-03-04T23:59:00Z"),
+		Day:    Totals{Key: "day", Requests: 2},
+		Week:   Totals{Key: "week", Requests: 3},
+	}
+
+	if got := usage.Totals(ByDay, at(t, "2026-03-04T23:59:30Z")); got.Requests != 2 {
+		t.Errorf("Totals(day) = %+v, want the cached day", got)
+	}
+	if got := usage.Totals(ByWeek, at(t, "2026-03-05T00:00:30Z")); got.Requests != 3 {
+		t.Errorf("Totals(week) = %+v, want the cached week", got)
+	}
+	// The day rolled over since the logs were read
+	if got := usage.Totals(ByDay, at(t, "2026-03-05T00:00:30Z")); got.Requests != 0 || got.Key != "day" {
+		t.Errorf("Totals(day) after midnight = %+v, want an empty day", got)
+	}
+}
+
+func TestUsageBlockStatus(t *testing.T) {
+	block := &Block{
+		Start:  at(t, "2026-03-04T08:00:00Z"),
+		First:  at(t, "2026-03-04T08:10:00Z"),
+		Last:   at(t, "2026-03-04T09:00:00Z"),
+		Tokens: Tokens{Output: 500},
+	}
+	usage := Usage{ReadAt: at(t, "2026-03-04T10:00:00Z"), Block: block, Peak: 4000}
+
+	status := usage.BlockStatus(at(t, "2026-03-04T10:30:00Z"), 0)
+	if status == nil || status.Limit != 4000 || status.Remaining != 150*time.Minute {
+		t.Errorf("BlockStatus(limit 0) = %+v, want the peak as limit and 2h30m left", status)
+	}
+	if status := usage.BlockStatus(at(t, "2026-03-04T10:30:00Z"), 1000); status == nil || status.Limit != 1000 {
+		t.Errorf("BlockStatus(limit 1000) = %+v, want the configured limit", status)
+	}
+
+	// The block closed since the logs were read
+	if status := usage.BlockStatus(at(t, "2026-03-04T13:00:00Z"), 0); status != nil {
+		t.Errorf("BlockStatus() after the block closed = %+v, want nil", status)
+	}
+	if status := (Usage{}).BlockStatus(at(t, "2026-03-04T10:30:00Z"), 0); status != nil {
+		t.Errorf("BlockStatus() without a block = %+v, want nil", status)
+	}
+}
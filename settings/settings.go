@@ -0,0 +1,327 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Scope selects which Claude Code settings file to edit
+type Scope string
+
+const (
+	ScopeUser    Scope = "user"    // ~/.claude/settings.json
+	ScopeProject Scope = "project" // <project>/.claude/settings.json, shared through version control
+	ScopeLocal   Scope = "local"   // <project>/.claude/settings.local.json, ignored by git
+)
+
+// statusLineKey is the top-level settings key Claude Code reads the status line command from
+const statusLineKey = "statusLine"
+
+// StatusLine is the statusLine block understood by Claude Code
+type StatusLine struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Padding int    `json:"padding"`
+}
+
+// member is a top-level key of the settings object with its raw value, kept in file order
+type member struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// Path returns the settings file for a scope. projectDir is ignored for the user scope.
+func Path(scope Scope, projectDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		return filepath.Join(home, ".claude", "settings.json"), nil
+	case ScopeProject:
+		return filepath.Join(projectDir, ".claude", "settings.json"), nil
+	case ScopeLocal:
+		return filepath.Join(projectDir, ".claude", "settings.local.json"), nil
+	default:
+		return "", fmt.Errorf("unknown scope %q (expected user, project or local)", scope)
+	}
+}
+
+// Install merges the statusLine block into the settings file at path, creating it if needed.
+// Other keys keep their values and order; duplicate statusLine keys collapse into the first.
+// Returns the backup path, empty when no file existed, and whether the file changed: an
+// identical block is left alone, without a backup.
+func Install(path string, statusLine StatusLine) (string, bool, error) {
+	value, err := json.Marshal(statusLine)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to encode statusLine: %w", err)
+	}
+
+	return edit(path, func(members []member) ([]member, bool) {
+		var kept []member
+		blocks, unchanged := 0, false
+		for _, m := range members {
+			if m.Key != statusLineKey {
+				kept = append(kept, m)
+				continue
+			}
+			if blocks == 0 {
+				unchanged = sameJSON(m.Value, value)
+				kept = append(kept, member{Key: statusLineKey, Value: value})
+			}
+			blocks++
+		}
+
+		if blocks == 0 {
+			return append(kept, member{Key: statusLineKey, Value: value}), true
+		}
+		return kept, blocks > 1 || !unchanged
+	})
+}
+
+// Uninstall removes every statusLine block from the settings file at path.
+// Returns the backup path and whether there was anything to remove.
+func Uninstall(path string) (string, bool, error) {
+	return edit(path, func(members []member) ([]member, bool) {
+		var kept []member
+		for _, m := range members {
+			if m.Key != statusLineKey {
+				kept = append(kept, m)
+			}
+		}
+		return kept, len(kept) < len(members)
+	})
+}
+
+// Read returns the current statusLine block, or nil when the file or key is missing. Of
+// duplicate keys the last one counts, as it does for Claude Code.
+func Read(path string) (*StatusLine, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, m := range slices.Backward(members) {
+		if m.Key == statusLineKey {
+			var statusLine StatusLine
+			if err := json.Unmarshal(m.Value, &statusLine); err != nil {
+				return nil, fmt.Errorf("%s: invalid statusLine: %w", path, err)
+			}
+			return &statusLine, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// edit applies modify to the settings object at path, backing up and atomically replacing the file.
+// The file is left untouched when modify reports no change.
+func edit(path string, modify func([]member) ([]member, bool)) (string, bool, error) {
+	original, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	members, err := parse(original)
+	if err != nil {
+		return "", false, fmt.Errorf("refusing to edit %s: %w", path, err)
+	}
+
+	members, changed := modify(members)
+	if !changed {
+		return "", false, nil
+	}
+
+	output, err := render(members)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Never write something Claude Code could fail to load
+	if !json.Valid(output) {
+		return "", false, fmt.Errorf("internal error: generated invalid JSON for %s", path)
+	}
+
+	mode := os.FileMode(0o644)
+	backup := ""
+	if exists {
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+
+		backup, err = writeBackup(path, original, mode)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	if err := writeAtomic(path, output, mode); err != nil {
+		return backup, false, err
+	}
+
+	return backup, true, nil
+}
+
+// sameJSON reports whether two JSON values are identical apart from whitespace
+func sameJSON(a, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return false
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
+
+// writeBackup copies data next to path under a timestamped name that never overwrites an older backup
+func writeBackup(path string, data []byte, mode os.FileMode) (string, error) {
+	base := fmt.Sprintf("%s.bak-%s", path, time.Now().Format("20060102-150405"))
+
+	for attempt := 0; attempt < 100; attempt++ {
+		backup := base
+		if attempt > 0 {
+			backup = fmt.Sprintf("%s-%d", base, attempt)
+		}
+
+		file, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create backup %s: %w", backup, err)
+		}
+
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write backup %s: %w", backup, err)
+		}
+
+		return backup, nil
+	}
+
+	return "", fmt.Errorf("failed to create backup for %s: too many backups this second", path)
+}
+
+// parse splits a settings object into its top-level members. Empty input is an empty object.
+func parse(data []byte) ([]member, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("invalid settings: top-level value must be an object")
+	}
+
+	var members []member
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		key := token.(string) // Object keys are always strings
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid JSON in %q: %w", key, err)
+		}
+		members = append(members, member{Key: key, Value: value})
+	}
+
+	// Closing brace, then nothing but whitespace
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the settings object")
+	}
+
+	return members, nil
+}
+
+// render serializes members as an indented object, preserving their order
+func render(members []member) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		var value bytes.Buffer
+		if err := json.Indent(&value, m.Value, "  ", "  "); err != nil {
+			return nil, fmt.Errorf("invalid JSON in %q: %w", m.Key, err)
+		}
+
+		buf.WriteString("\n  ")
+		buf.Write(key)
+		buf.WriteString(": ")
+		buf.Write(value.Bytes())
+	}
+
+	if len(members) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// writeAtomic writes data to a temporary file next to path and renames it into place
+func writeAtomic(path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
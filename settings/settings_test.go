@@ -0,0 +1,291 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testStatusLine = StatusLine{Type: "command", Command: "cc-status-line --style nerd"}
+
+func writeSettings(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readSettings(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// backups returns the backup files written next to path
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(path + ".bak-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestInstallPreservesOtherKeys(t *testing.T) {
+	original := `{
+  "model": "opus",
+  "statusLine": {"type": "command", "command": "old"},
+  "hooks": {"Stop": [{"command": "notify"}]},
+  "env": {"FOO": "bar"}
+}
+`
+	path := writeSettings(t, original)
+
+	backup, changed, err := Install(path, testStatusLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("Install() reported no change")
+	}
+
+	want := `{
+  "model": "opus",
+  "statusLine": {
+    "type": "command",
+    "command": "cc-status-line --style nerd",
+    "padding": 0
+  },
+  "hooks": {
+    "Stop": [
+      {
+        "command": "notify"
+      }
+    ]
+  },
+  "env": {
+    "FOO": "bar"
+  }
+}
+`
+	if got := readSettings(t, path); got != want {
+		t.Errorf("settings =\n%s\nwant\n%s", got, want)
+	}
+
+	if backup == "" {
+		t.Fatal("Install() returned no backup for an existing file")
+	}
+	if got := readSettings(t, backup); got != original {
+		t.Errorf("backup =\n%s\nwant the original file", got)
+	}
+}
+
+func TestInstallCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude", "settings.json")
+
+	backup, changed, err := Install(path, testStatusLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || backup != "" {
+		t.Errorf("Install() = (%q, %v), want a new file without backup", backup, changed)
+	}
+
+	statusLine, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusLine == nil || *statusLine != testStatusLine {
+		t.Errorf("Read() = %+v, want %+v", statusLine, testStatusLine)
+	}
+}
+
+func TestInstallUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if _, _, err := Install(path, testStatusLine); err != nil {
+		t.Fatal(err)
+	}
+	before := readSettings(t, path)
+
+	backup, changed, err := Install(path, testStatusLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || backup != "" {
+		t.Errorf("Install() = (%q, %v), want no change for an identical block", backup, changed)
+	}
+	if got := readSettings(t, path); got != before {
+		t.Errorf("settings rewritten:\n%s", got)
+	}
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("backups = %v, want none", files)
+	}
+}
+
+func TestEditRefusesInvalidJSON(t *testing.T) {
+	tests := map[string]string{
+		"syntax error":  `{"model": }`,
+		"not an object": `["statusLine"]`,
+		"trailing data": `{"model": "opus"} {}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeSettings(t, content)
+
+			if _, _, err := Install(path, testStatusLine); err == nil {
+				t.Error("Install() succeeded on invalid settings")
+			}
+			if _, _, err := Uninstall(path); err == nil {
+				t.Error("Uninstall() succeeded on invalid settings")
+			}
+
+			if got := readSettings(t, path); got != content {
+				t.Errorf("settings modified to %q", got)
+			}
+			if files := backups(t, path); len(files) != 0 {
+				t.Errorf("backups = %v, want none", files)
+			}
+		})
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	path := writeSettings(t, `{"model": "opus", "statusLine": {"type": "command", "command": "x"}, "env": {}}`)
+
+	backup, changed, err := Uninstall(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || backup == "" {
+		t.Fatalf("Uninstall() = (%q, %v), want a change with backup", backup, changed)
+	}
+
+	want := "{\n  \"model\": \"opus\",\n  \"env\": {}\n}\n"
+	if got := readSettings(t, path); got != want {
+		t.Errorf("settings =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUninstallWithoutStatusLine(t *testing.T) {
+	original := `{"model": "opus"}`
+	path := writeSettings(t, original)
+
+	backup, changed, err := Uninstall(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || backup != "" {
+		t.Errorf("Uninstall() = (%q, %v), want no change", backup, changed)
+	}
+	if got := readSettings(t, path); got != original {
+		t.Errorf("settings modified to %q", got)
+	}
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("backups = %v, want none", files)
+	}
+
+	// A missing file has nothing to remove either
+	missing := filepath.Join(t.TempDir(), "settings.json")
+	if _, changed, err := Uninstall(missing); err != nil || changed {
+		t.Errorf("Uninstall(missing) = (%v, %v), want no change", changed, err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Uninstall(missing) created the file")
+	}
+}
+
+func TestDuplicateStatusLine(t *testing.T) {
+	duplicated := `{
+  "statusLine": {"type": "command", "command": "first"},
+  "model": "opus",
+  "statusLine": {"type": "command", "command": "last"}
+}`
+
+	t.Run("read", func(t *testing.T) {
+		path := writeSettings(t, duplicated)
+
+		statusLine, err := Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusLine == nil || statusLine.Command != "last" {
+			t.Errorf("Read() = %+v, want the last block", statusLine)
+		}
+	})
+
+	t.Run("install", func(t *testing.T) {
+		path := writeSettings(t, duplicated)
+
+		if _, changed, err := Install(path, testStatusLine); err != nil || !changed {
+			t.Fatalf("Install() = (%v, %v), want a change", changed, err)
+		}
+
+		got := readSettings(t, path)
+		if n := strings.Count(got, `"statusLine"`); n != 1 {
+			t.Errorf("settings keep %d statusLine keys:\n%s", n, got)
+		}
+		if !strings.HasPrefix(got, "{\n  \"statusLine\"") {
+			t.Errorf("statusLine moved from the first key's place:\n%s", got)
+		}
+	})
+
+	t.Run("install identical first block", func(t *testing.T) {
+		// Identical to the first block but shadowed by the second, so it still needs a rewrite
+		path := writeSettings(t, `{
+  "statusLine": {"type": "command", "command": "cc-status-line --style nerd", "padding": 0},
+  "statusLine": {"type": "command", "command": "last"}
+}`)
+
+		if _, changed, err := Install(path, testStatusLine); err != nil || !changed {
+			t.Fatalf("Install() = (%v, %v), want a change", changed, err)
+		}
+		statusLine, err := Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusLine == nil || *statusLine != testStatusLine {
+			t.Errorf("Read() = %+v, want %+v", statusLine, testStatusLine)
+		}
+	})
+
+	t.Run("uninstall", func(t *testing.T) {
+		path := writeSettings(t, duplicated)
+
+		if _, changed, err := Uninstall(path); err != nil || !changed {
+			t.Fatalf("Uninstall() = (%v, %v), want a change", changed, err)
+		}
+		if got := readSettings(t, path); strings.Contains(got, "statusLine") {
+			t.Errorf("statusLine left behind:\n%s", got)
+		}
+	})
+}
+
+func TestWriteBackupUniqueNames(t *testing.T) {
+	path := writeSettings(t, `{}`)
+
+	first, err := writeBackup(path, []byte("one"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := writeBackup(path, []byte("two"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatalf("both backups written to %s", first)
+	}
+	if readSettings(t, first) != "one" || readSettings(t, second) != "two" {
+		t.Error("backup contents mixed up")
+	}
+}
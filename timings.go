@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// latencyBudget is the refresh latency the README promises
+const latencyBudget = 100 * time.Millisecond
+
+// Timing output modes for --timings
+const (
+	timingsOff  = ""
+	timingsLine = "line" // Append a line below the status line
+	timingsLog  = "log"  // Write to the debug log
+)
+
+// timingsFlag accepts a bare --timings (line) as well as --timings=line|log
+type timingsFlag string
+
+func (f *timingsFlag) String() string { return string(*f) }
+
+// IsBoolFlag lets the flag package accept --timings without a value
+func (f *timingsFlag) IsBoolFlag() bool { return true }
+
+func (f *timingsFlag) Set(value string) error {
+	switch value {
+	case "true", timingsLine:
+		*f = timingsLine
+	case "false":
+		*f = timingsOff
+	case timingsLog:
+		*f = timingsLog
+	default:
+		return fmt.Errorf("expected line or log, got %q", value)
+	}
+	return nil
+}
+
+// phase is the duration of one pipeline step
+type phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// phaseTimer measures consecutive pipeline phases
+type phaseTimer struct {
+	start  time.Time
+	last   time.Time
+	phases []phase
+}
+
+func newPhaseTimer() *phaseTimer {
+	now := time.Now()
+	return &phaseTimer{start: now, last: now}
+}
+
+// mark ends the current phase under the given name and starts the next one
+func (t *phaseTimer) mark(name string) {
+	now := time.Now()
+	t.phases = append(t.phases, phase{Name: name, Duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// total returns the time since the timer started
+func (t *phaseTimer) total() time.Duration {
+	return t.last.Sub(t.start)
+}
+
+// String formats the phases, e.g. "parse 0.12ms | tokens 0.01ms | git 8.31ms | render 0.45ms | total 8.89ms"
+func (t *phaseTimer) String() string {
+	parts := make([]string, 0, len(t.phases)+1)
+	for _, p := range t.phases {
+		parts = append(parts, fmt.Sprintf("%s %s", p.Name, formatMillis(p.Duration)))
+	}
+
+	total := fmt.Sprintf("total %s", formatMillis(t.total()))
+	if t.total() > latencyBudget {
+		total += fmt.Sprintf(" (over %s budget)", latencyBudget)
+	}
+
+	return strings.Join(append(parts, total), " | ")
+}
+
+// formatMillis renders a duration in milliseconds with two decimals
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d.Microseconds())/1000)
+}
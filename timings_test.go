@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/config"
+)
+
+func TestTimingsFlag(t *testing.T) {
+	tests := map[string]string{
+		"":                 timingsOff,
+		"--timings":        timingsLine,
+		"--timings=line":   timingsLine,
+		"--timings=true":   timingsLine,
+		"--timings=log":    timingsLog,
+		"--timings=false":  timingsOff,
+		"--timings=always": "error",
+	}
+
+	for arg, want := range tests {
+		var args []string
+		if arg != "" {
+			args = []string{arg}
+		}
+
+		var output strings.Builder
+		opts, err := parseRenderFlags(args, config.Default(), os.Getenv, &output)
+		if want == "error" {
+			if err == nil {
+				t.Errorf("parseRenderFlags(%q) accepted an invalid value", arg)
+			}
+			continue
+		}
+		if err != nil || opts.Timings != want {
+			t.Errorf("parseRenderFlags(%q) = %q, %v, want %q", arg, opts.Timings, err, want)
+		}
+	}
+}
+
+func TestPhaseTimer(t *testing.T) {
+	start := time.Now()
+	timer := &phaseTimer{
+		start: start,
+		last:  start.Add(3 * time.Millisecond),
+		phases: []phase{
+			{Name: "parse", Duration: 1250 * time.Microsecond},
+			{Name: "git", Duration: 1750 * time.Microsecond},
+		},
+	}
+
+	if got, want := timer.String(), "parse 1.25ms | git 1.75ms | total 3.00ms"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	timer.last = start.Add(150 * time.Millisecond)
+	if got := timer.String(); !strings.HasSuffix(got, "total 150.00ms (over 100ms budget)") {
+		t.Errorf("String() = %q, want the budget overrun flagged", got)
+	}
+
+	timer = newPhaseTimer()
+	timer.mark("parse")
+	timer.mark("render")
+	if len(timer.phases) != 2 || timer.phases[0].Name != "parse" || timer.phases[1].Name != "render" {
+		t.Errorf("phases = %+v, want parse and render", timer.phases)
+	}
+	if sum := timer.phases[0].Duration + timer.phases[1].Duration; sum != timer.total() {
+		t.Errorf("phases sum to %v, total() = %v", sum, timer.total())
+	}
+}
+
+func TestWithTimingsWarnsOnStderr(t *testing.T) {
+	// A debug log below a regular file can't be created
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr strings.Builder
+	opts := renderOptions{Timings: timingsLog, DebugLog: filepath.Join(blocker, "debug.log"), Stderr: &stderr}
+	if got := withTimings("status", newPhaseTimer(), opts); got != "status" {
+		t.Errorf("withTimings() = %q, want the status line unchanged", got)
+	}
+	if !strings.Contains(stderr.String(), "cc-status-line warning:") {
+		t.Errorf("stderr = %q, want the debug log failure reported", stderr.String())
+	}
+
+	opts.Timings = timingsLine
+	if got := withTimings("status", newPhaseTimer(), opts); !strings.HasPrefix(got, "status\ntotal ") {
+		t.Errorf("withTimings() = %q, want a timings line appended", got)
+	}
+}
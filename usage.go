@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/display"
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+// usagePeriods maps the usage segments to the period they total
+var usagePeriods = map[string]sessionlog.Grouping{
+	"usage-day":  sessionlog.ByDay,
+	"usage-week": sessionlog.ByWeek,
+}
+
+// collectUsage totals Claude Code's session logs for each enabled usage segment and finds
+// the current usage block when the block segment is enabled. The logs are read through the
+// usage cache, off the render path once they have been read; unreadable logs mark the
+// usage segments as failed.
+func collectUsage(opts renderOptions, failed map[string]bool, onPanic func(string, any, []byte)) (map[string]*sessionlog.Totals, *sessionlog.BlockStatus) {
+	enabled := false
+	for _, name := range opts.Segments {
+		if _, ok := usagePeriods[name]; ok || name == "block" {
+			enabled = true
+		}
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	dir := sessionlog.ProjectsDir(opts.getenv(), homeDir(opts.getenv()))
+	now := time.Now()
+	usage := collect("usage", failed, onPanic, func() *sessionlog.Usage {
+		usage, err := opts.usageCache().Get(dir, now)
+		if err != nil {
+			display.MarkSourceFailed(failed, "usage")
+		}
+		return usage
+	})
+	if usage == nil {
+		return nil, nil
+	}
+
+	var totals map[string]*sessionlog.Totals
+	var block *sessionlog.BlockStatus
+	for _, name := range opts.Segments {
+		if name == "block" {
+			block = usage.BlockStatus(now, opts.BlockTokenLimit)
+		}
+		if period, ok := usagePeriods[name]; ok {
+			if totals == nil {
+				totals = make(map[string]*sessionlog.Totals)
+			}
+			sum := usage.Totals(period, now)
+			totals[name] = &sum
+		}
+	}
+	return totals, block
+}
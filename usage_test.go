@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DieGopherLT/cc-status-line/sessionlog"
+)
+
+func TestCollectUsage(t *testing.T) {
+	configDir := t.TempDir()
+	logPath := filepath.Join(configDir, "projects", "-work-api", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := fmt.Sprintf(`{"type":"assistant","sessionId":"a","cwd":"/work/api","requestId":"req_1","timestamp":%q,`+
+		`"message":{"id":"msg_1","model":"claude-sonnet-4","usage":{"input_tokens":10,"output_tokens":200}}}`+"\n",
+		time.Now().Add(-time.Second).UTC().Format(time.RFC3339))
+	if err := os.WriteFile(logPath, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := sessionlog.NewCache("")
+	cache.Deadline = time.Minute
+	opts := renderOptions{
+		Segments:        []string{"usage-day", "block"},
+		BlockTokenLimit: 1000,
+		Getenv:          func(key string) string { return map[string]string{"CLAUDE_CONFIG_DIR": configDir}[key] },
+		UsageCache:      cache,
+	}
+
+	failed := make(map[string]bool)
+	totals, block := collectUsage(opts, failed, nil)
+	if failed["usage"] {
+		t.Fatal("usage marked as failed")
+	}
+	if day := totals["usage-day"]; day == nil || day.Requests != 1 || day.Tokens.Output != 200 {
+		t.Errorf("usage-day = %+v, want the logged response", day)
+	}
+	if _, ok := totals["usage-week"]; ok {
+		t.Error("totaled the disabled usage-week segment")
+	}
+	if block == nil || block.Limit != 1000 {
+		t.Errorf("block = %+v, want the open block against the configured limit", block)
+	}
+
+	// Without a usage segment the logs aren't read
+	opts.Segments = []string{"cost"}
+	opts.UsageCache = sessionlog.NewCache("")
+	if totals, block := collectUsage(opts, failed, nil); totals != nil || block != nil {
+		t.Errorf("collectUsage() without usage segments = %v, %v; want nothing", totals, block)
+	}
+}